@@ -0,0 +1,186 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ArgGroupName is the name of the group of the positional arguments,
+// which is not one of the option groups. See Config.ArgGroup.
+const ArgGroupName = "ARGS"
+
+// RegisterArg registers the positional argument, which is assigned from
+// the rest CLI arguments in the order of the registration after parsing.
+//
+// The argument is declared by the option, which has the name, the type,
+// the default, the help and the validators, and is required if it implements
+// RequiredOpt, such as
+//
+//    Int("count", 1, "help").(RequiredSetter).SetRequired(true)
+//
+// The value is parsed and validated like the option, and is got by the typed
+// getters of ArgGroup, such as c.ArgGroup().Int("count").
+//
+// The slice or map argument is variadic, which takes all the rest arguments,
+// so it must be the last one, such as Strings("files", nil, "help"),
+// or it will panic when registering the argument after it.
+//
+// If the positional arguments are registered, it's an error that there are
+// more rest CLI arguments than them. And Args still returns all the rest CLI
+// arguments.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) RegisterArg(opt Opt) {
+	c.registerArg(nil, opt)
+}
+
+// RegisterArgs registers a set of positional arguments in order.
+// See RegisterArg.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) RegisterArgs(opts []Opt) {
+	for _, opt := range opts {
+		c.RegisterArg(opt)
+	}
+}
+
+// RegisterArg is the same as Config.RegisterArg, but the argument belongs
+// to the command, which is only used when the command is selected.
+func (cmd *Command) RegisterArg(opt Opt) {
+	cmd.conf.registerArg(cmd, opt)
+}
+
+// RegisterArgs is the same as Config.RegisterArgs, but the arguments belong
+// to the command.
+func (cmd *Command) RegisterArgs(opts []Opt) {
+	for _, opt := range opts {
+		cmd.RegisterArg(opt)
+	}
+}
+
+func (c *Config) registerArg(cmd *Command, opt Opt) {
+	c.panicIsParsed(true)
+	for _, o := range c.argOpts {
+		if isMultiOpt(o.opt) && !cmd.isExclusive(o.cmd) {
+			panic(fmt.Errorf("the argument '%s' is after the variadic argument '%s'",
+				opt.Name(), o.opt.Name()))
+		}
+	}
+
+	if o := c.ArgGroup().registerOpt(cmd, false, opt); o != nil {
+		c.argOpts = append(c.argOpts, o)
+	}
+}
+
+// ArgGroup returns the group of the positional arguments, which is used to
+// get the value of the positional argument. See RegisterArg.
+func (c *Config) ArgGroup() *OptGroup {
+	if c.argGroup == nil {
+		c.argGroup = newOptGroup(ArgGroupName, ArgGroupName, c)
+	}
+	return c.argGroup
+}
+
+// getArgOpts returns the positional arguments of the global and the selected
+// command in the order of the registration.
+func (c *Config) getArgOpts() []Opt {
+	opts := make([]Opt, 0, len(c.argOpts))
+	for _, o := range c.argOpts {
+		if c.isActiveCommand(o.cmd) {
+			opts = append(opts, o.opt)
+		}
+	}
+	return opts
+}
+
+// parseArgs assigns the rest CLI arguments to the positional arguments,
+// and checks the required arguments.
+func (c *Config) parseArgs() (err error) {
+	opts := c.getArgOpts()
+	if len(opts) == 0 {
+		return nil
+	}
+
+	g := c.ArgGroup()
+	args := c.args
+	for _, opt := range opts {
+		if len(args) == 0 {
+			break
+		}
+
+		var value interface{}
+		if isMultiOpt(opt) {
+			value, args = args, nil
+		} else {
+			value, args = args[0], args[1:]
+		}
+
+		if err = g.setOptValue(0, opt.Name(), value, false); err != nil {
+			return fmt.Errorf("invalid argument '%s': %s", opt.Name(), err)
+		}
+	}
+
+	if len(args) > 0 {
+		return fmt.Errorf("too many arguments: %s", strings.Join(args, " "))
+	}
+
+	for _, opt := range opts {
+		if _, ok := g.values[opt.Name()]; !ok && IsRequiredOpt(opt) {
+			return fmt.Errorf("the required argument '%s' is missing", opt.Name())
+		}
+	}
+	return g.checkRequiredOption()
+}
+
+// getUsageArgs returns the positional arguments displayed in the usage,
+// the flag of which is like "<name>", "[name]" or "<name>...".
+func (c *Config) getUsageArgs() []UsageOpt {
+	opts := c.getArgOpts()
+	args := make([]UsageOpt, 0, len(opts))
+	for _, opt := range opts {
+		arg := c.getUsageOpt(c.ArgGroup(), opt)
+		arg.EnvVars = nil
+		if isEmptyMulti(opt.Default()) {
+			arg.Default = ""
+		}
+
+		arg.Flag = opt.Name()
+		if isMultiOpt(opt) {
+			arg.Flag += "..."
+		}
+		if arg.Required {
+			arg.Flag = "<" + arg.Flag + ">"
+		} else {
+			arg.Flag = "[" + arg.Flag + "]"
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// isEmptyMulti reports whether v is an empty slice or map.
+func isEmptyMulti(v interface{}) bool {
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newArgsConfig() *Config {
+	conf := NewConfig().AddParser(NewGetoptCliParser(true))
+	conf.RegisterArgs([]Opt{
+		Str("src", "", "the source").(RequiredSetter).SetRequired(true),
+		Int("count", 1, "the count").AddValidators(NewIntegerRangeValidator(1, 10)),
+		Strings("files", nil, "the files"),
+	})
+	return conf
+}
+
+func TestArgs(t *testing.T) {
+	conf := newArgsConfig()
+	if err := conf.Parse("--", "src", "3", "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	args := conf.ArgGroup()
+	if v := args.String("src"); v != "src" {
+		t.Errorf("expect '%s', but got '%s'", "src", v)
+	}
+	if v := args.Int("count"); v != 3 {
+		t.Errorf("expect %d, but got %d", 3, v)
+	}
+	if v := args.Strings("files"); !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("unexpected files %q", v)
+	}
+
+	conf = newArgsConfig()
+	if err := conf.Parse("src"); err != nil {
+		t.Fatal(err)
+	} else if v := conf.ArgGroup().Int("count"); v != 1 {
+		t.Errorf("expect %d, but got %d", 1, v)
+	}
+
+	errs := map[string][]string{
+		"the required argument 'src' is missing": {},
+		"invalid argument 'count'":               {"src", "11"},
+	}
+	for msg, args := range errs {
+		conf = newArgsConfig()
+		if err := conf.Parse(args...); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("expect the error '%s', but got %v", msg, err)
+		}
+	}
+
+	conf = NewConfig().AddParser(NewGetoptCliParser(true))
+	conf.RegisterArg(Str("name", "", ""))
+	if err := conf.Parse("a", "b"); err == nil || !strings.Contains(err.Error(), "too many arguments") {
+		t.Errorf("expect the error of too many arguments, but got %v", err)
+	}
+}
+
+func TestArgsUsage(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	conf := newArgsConfig()
+	conf.SetUsageWriter(buf)
+	if err := conf.Parse("--help"); err != ErrHelp {
+		t.Fatalf("expect ErrHelp, but got %v", err)
+	}
+
+	expected := `Usage: ` + conf.GetUsage().Program + ` [OPTIONS] <src> [count] [files...]
+
+Arguments:
+  src string
+        the source [required]
+  count int
+        the count (default: 1) (in [1, 10])
+  files []string
+        the files
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected usage:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestArgsVariadicNotLast(t *testing.T) {
+	conf := NewConfig()
+	conf.RegisterArg(Str("name", "", ""))
+	conf.NewCommand("cmd1", "").RegisterArg(Strings("files", nil, ""))
+
+	// The argument of the exclusive command is allowed.
+	conf.NewCommand("cmd2", "").RegisterArg(Str("file", "", ""))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expect a panic, but got nil")
+		}
+	}()
+	conf.RegisterArg(Str("last", "", ""))
+}
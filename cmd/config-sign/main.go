@@ -0,0 +1,118 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command config-sign signs the config files by the ed25519 private key,
+// which can be verified by config.NewEd25519Verifier.
+//
+// Generate a pair of keys, "KEY.key" and "KEY.pub":
+//
+//    config-sign -genkey KEY
+//
+// Sign the config files into the detached signature files, such as "app.ini.sig":
+//
+//    config-sign -key KEY.key app.ini app.properties
+//
+// Sign the config files and embed the signature into them:
+//
+//    config-sign -key KEY.key -embed app.ini app.properties
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	config "github.com/xgfone/go-config"
+)
+
+func main() {
+	genkey := flag.String("genkey", "", "Generate the key pair, PREFIX.key and PREFIX.pub.")
+	keyfile := flag.String("key", "", "The path of the ed25519 private key file.")
+	embed := flag.Bool("embed", false, "Embed the signature into the config file.")
+	flag.Parse()
+
+	if err := run(*genkey, *keyfile, *embed, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(genkey, keyfile string, embed bool, files []string) error {
+	if genkey != "" {
+		return generateKey(genkey)
+	}
+
+	if keyfile == "" {
+		return fmt.Errorf("missing the private key file")
+	} else if len(files) == 0 {
+		return fmt.Errorf("missing the config files")
+	}
+
+	data, err := ioutil.ReadFile(keyfile)
+	if err != nil {
+		return err
+	}
+	key, err := config.ParseEd25519PrivateKey(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid private key file '%s': %s", keyfile, err)
+	}
+
+	for _, file := range files {
+		if err = signFile(key, file, embed); err != nil {
+			return fmt.Errorf("failed to sign '%s': %s", file, err)
+		}
+	}
+	return nil
+}
+
+func generateKey(prefix string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	privdata := base64.StdEncoding.EncodeToString(priv) + "\n"
+	if err = ioutil.WriteFile(prefix+".key", []byte(privdata), 0600); err != nil {
+		return err
+	}
+	pubdata := base64.StdEncoding.EncodeToString(pub) + "\n"
+	return ioutil.WriteFile(prefix+".pub", []byte(pubdata), 0644)
+}
+
+func signFile(key ed25519.PrivateKey, file string, embed bool) error {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	if embed {
+		if data, err = config.EmbedEd25519Signature(key, file, data); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(file, data, fi.Mode())
+	}
+
+	sig := config.SignEd25519(key, file, data)
+	return ioutil.WriteFile(file+config.SignatureFileSuffix, sig, 0644)
+}
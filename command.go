@@ -0,0 +1,234 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Command is a sub-command of the config manager, such as "serve" of
+// "app serve", which owns its options and sub-commands.
+//
+// The options registered by the command only take effect when the command
+// or its sub-command is selected, but the global options, which are registered
+// by Config directly, always take effect. So the parsers only handle the options
+// of the selected command path and the global options.
+type Command struct {
+	conf   *Config
+	parent *Command
+
+	name string
+	help string
+	cmds []*Command
+}
+
+func newCommand(conf *Config, parent *Command, name, help string) *Command {
+	if name == "" || strings.HasPrefix(name, "-") {
+		panic(fmt.Errorf("invalid command name '%s'", name))
+	}
+	return &Command{conf: conf, parent: parent, name: name, help: help}
+}
+
+func addCommand(cmds []*Command, cmd *Command) []*Command {
+	for _, c := range cmds {
+		if c.name == cmd.name {
+			panic(fmt.Errorf("the command '%s' has been registered", cmd.FullName()))
+		}
+	}
+	return append(cmds, cmd)
+}
+
+func getCommand(cmds []*Command, name string) *Command {
+	for _, cmd := range cmds {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// NewCommand news and returns a top command named name.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) NewCommand(name, help string) *Command {
+	c.panicIsParsed(true)
+	cmd := newCommand(c, nil, name, help)
+	c.commands = addCommand(c.commands, cmd)
+	return cmd
+}
+
+// Commands returns the sub-commands of the selected command,
+// or the top commands if no command is selected.
+func (c *Config) Commands() []*Command {
+	if c.command != nil {
+		return c.command.Commands()
+	}
+	return append([]*Command{}, c.commands...)
+}
+
+// Command returns the selected command, which is nil if no command is selected.
+//
+// For example, it returns the command "add" for "app admin user add".
+func (c *Config) Command() *Command {
+	return c.command
+}
+
+// SelectCommand selects the sub-command named name of the selected command,
+// or the top command if no command is selected, then returns it.
+//
+// Return nil if the command does not exist.
+//
+// Notice: it should be called by the CLI parser when it meets the positional
+// argument, and the parser should parse the options of the selected command
+// from the rest arguments.
+func (c *Config) SelectCommand(name string) *Command {
+	cmd := getCommand(c.Commands(), name)
+	if cmd == nil {
+		return nil
+	}
+
+	c.command = cmd
+	for _, group := range c.groups {
+		group.selectCommand()
+	}
+	if c.argGroup != nil {
+		c.argGroup.selectCommand()
+	}
+	c.debug("Select the command '%s'", cmd.FullName())
+	return cmd
+}
+
+// isActiveCommand reports whether cmd is the selected command or its ancestor.
+//
+// The nil command represents the global, so it is always active.
+func (c *Config) isActiveCommand(cmd *Command) bool {
+	if cmd == nil {
+		return true
+	}
+
+	for selected := c.command; selected != nil; selected = selected.parent {
+		if selected == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the name of the command.
+func (cmd *Command) Name() string {
+	return cmd.name
+}
+
+// Help returns the help of the command.
+func (cmd *Command) Help() string {
+	return cmd.help
+}
+
+// FullName returns the full name of the command, which is the names
+// from the top command separated by the whitespace, such as "admin user add".
+func (cmd *Command) FullName() string {
+	if cmd.parent == nil {
+		return cmd.name
+	}
+	return cmd.parent.FullName() + " " + cmd.name
+}
+
+// Parent returns the parent command, which is nil for the top command.
+func (cmd *Command) Parent() *Command {
+	return cmd.parent
+}
+
+// Commands returns all the sub-commands.
+func (cmd *Command) Commands() []*Command {
+	return append([]*Command{}, cmd.cmds...)
+}
+
+// Command returns the sub-command named name, which is nil if not exist.
+func (cmd *Command) Command(name string) *Command {
+	return getCommand(cmd.cmds, name)
+}
+
+// NewCommand news and returns a sub-command named name.
+//
+// If parsed, it will panic when calling it.
+func (cmd *Command) NewCommand(name, help string) *Command {
+	cmd.conf.panicIsParsed(true)
+	sub := newCommand(cmd.conf, cmd, name, help)
+	cmd.cmds = addCommand(cmd.cmds, sub)
+	return sub
+}
+
+// isExclusive reports whether cmd and other are not on the same command path,
+// that's, neither is the ancestor of the other.
+func (cmd *Command) isExclusive(other *Command) bool {
+	if cmd == nil || other == nil {
+		return false
+	}
+
+	for c := cmd; c != nil; c = c.parent {
+		if c == other {
+			return false
+		}
+	}
+	for c := other; c != nil; c = c.parent {
+		if c == cmd {
+			return false
+		}
+	}
+	return true
+}
+
+// RegisterStruct is the same as Config.RegisterStruct, but the options belong
+// to the command.
+func (cmd *Command) RegisterStruct(group string, s interface{}) {
+	cmd.conf.registerStruct(cmd, group, s, false)
+}
+
+// RegisterCliStruct is the same as Config.RegisterCliStruct, but the options
+// belong to the command.
+func (cmd *Command) RegisterCliStruct(group string, s interface{}) {
+	cmd.conf.registerStruct(cmd, group, s, true)
+}
+
+// RegisterCliOpt is the same as Config.RegisterCliOpt, but the option belongs
+// to the command.
+func (cmd *Command) RegisterCliOpt(group string, opt Opt) {
+	cmd.conf.registerOpt(cmd, group, true, opt)
+}
+
+// RegisterCliOpts is the same as Config.RegisterCliOpts, but the options
+// belong to the command.
+func (cmd *Command) RegisterCliOpts(group string, opts []Opt) {
+	for _, opt := range opts {
+		cmd.RegisterCliOpt(group, opt)
+	}
+}
+
+// RegisterOpt is the same as Config.RegisterOpt, but the option belongs
+// to the command.
+func (cmd *Command) RegisterOpt(group string, opt Opt) {
+	cmd.conf.registerOpt(cmd, group, false, opt)
+}
+
+// RegisterOpts is the same as Config.RegisterOpts, but the options belong
+// to the command.
+func (cmd *Command) RegisterOpts(group string, opts []Opt) {
+	for _, opt := range opts {
+		cmd.RegisterOpt(group, opt)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type migrateConfig struct {
+	Target string `cli:"true"`
+}
+
+func (m migrateConfig) Validate() error {
+	return fmt.Errorf("the validator of the unselected command is called")
+}
+
+func newCommandConfig(newCli func() Parser) *Config {
+	conf := NewConfig().AddParser(newCli(), NewEnvVarParser("cmd"))
+	conf.RegisterCliOpt("", BoolOpt("d", "debug", false, ""))
+
+	serve := conf.NewCommand("serve", "Start the server")
+	serve.RegisterCliOpts("", []Opt{IntOpt("p", "port", 80, ""), Str("addr", "", "")})
+
+	migrate := conf.NewCommand("migrate", "Migrate the database")
+	migrate.RegisterCliOpt("", IntOpt("p", "port", 3306, ""))
+	migrate.RegisterCliStruct("migrate", &migrateConfig{})
+
+	user := conf.NewCommand("admin", "").NewCommand("user", "")
+	user.NewCommand("add", "").RegisterCliOpt("", Str("name", "", ""))
+	user.NewCommand("del", "").RegisterCliOpt("", Str("name", "", ""))
+
+	return conf
+}
+
+func TestCommand(t *testing.T) {
+	os.Setenv("CMD_ADDR", "127.0.0.1")
+	defer os.Unsetenv("CMD_ADDR")
+
+	newClis := []func() Parser{
+		func() Parser { return NewFlagCliParser(nil, true) },
+		func() Parser { return NewGetoptCliParser(true) },
+		func() Parser { return NewPflagCliParser(nil, true) },
+	}
+	for _, newCli := range newClis {
+		cli := newCli()
+		conf := newCommandConfig(newCli)
+		if err := conf.Parse("--debug", "serve", "--port", "8080", "arg"); err != nil {
+			t.Fatalf("%s: %s", cli.Name(), err)
+		}
+
+		if cmd := conf.Command(); cmd == nil || cmd.FullName() != "serve" {
+			t.Errorf("%s: unexpected command %v", cli.Name(), cmd)
+		}
+		if !conf.Bool("debug") || conf.Int("port") != 8080 || conf.String("addr") != "127.0.0.1" {
+			t.Errorf("%s: debug=%v, port=%d, addr=%s", cli.Name(), conf.Bool("debug"),
+				conf.Int("port"), conf.String("addr"))
+		}
+		if conf.HasGroup("migrate") && len(conf.Group("migrate").AllOpts()) != 0 {
+			t.Errorf("%s: the options of migrate are visible", cli.Name())
+		}
+		if args := conf.Args(); !reflect.DeepEqual(args, []string{"arg"}) {
+			t.Errorf("%s: unexpected rest arguments %q", cli.Name(), args)
+		}
+
+		conf = newCommandConfig(newCli)
+		if err := conf.Parse("admin", "user", "add", "--name", "xgfone"); err != nil {
+			t.Fatalf("%s: %s", cli.Name(), err)
+		}
+		if cmd := conf.Command(); cmd == nil || cmd.FullName() != "admin user add" {
+			t.Errorf("%s: unexpected command %v", cli.Name(), cmd)
+		}
+		if v := conf.String("name"); v != "xgfone" {
+			t.Errorf("%s: expect '%s', but got '%s'", cli.Name(), "xgfone", v)
+		}
+
+		conf = newCommandConfig(newCli)
+		if err := conf.Parse("migrate"); err == nil {
+			t.Errorf("%s: expect the error of the struct validator", cli.Name())
+		} else if v := conf.Int("port"); v != 3306 {
+			t.Errorf("%s: expect %d, but got %d", cli.Name(), 3306, v)
+		}
+	}
+}
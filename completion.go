@@ -0,0 +1,335 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// CompleteCommand is the hidden command to output the completion candidates
+// of the last CLI argument, such as "app __complete serve --po", which is
+// called by the completion scripts. See Config.Complete.
+const CompleteCommand = "__complete"
+
+// CompleteFileDirective is the prefix of the completion directive line, which
+// asks the shell to complete the file path. It may be followed by a whitespace
+// and the allowed extensions separated by the whitespace, such as ":file ini".
+const CompleteFileDirective = ":file"
+
+// ErrCompletion is returned by Config.Parse when giving the hidden command
+// CompleteCommand, after outputting the completion candidates into
+// the completion writer. The program should exit without doing anything else.
+// See Config.SetCompletionWriter.
+var ErrCompletion = fmt.Errorf("the completion candidates have been output")
+
+// SetCompletionWriter sets the writer of the completion candidates,
+// which is os.Stdout by default.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) SetCompletionWriter(w io.Writer) *Config {
+	c.panicIsParsed(true)
+	c.completionWriter = w
+	return c
+}
+
+type completeOpt struct {
+	long  string
+	short string
+	opt   Opt
+}
+
+func (o completeOpt) takeValue() bool {
+	_, ok := o.opt.Zero().(bool)
+	return !ok && !IsCounterOpt(o.opt)
+}
+
+func (c *Config) getCompleteOpts() []completeOpt {
+	var opts []completeOpt
+	for _, group := range c.Groups() {
+		for _, opt := range group.CliOpts() {
+			long, short := c.cliFlags(group.FullName(), opt)
+			opts = append(opts, completeOpt{long: long, short: short, opt: opt})
+		}
+	}
+	sort.Slice(opts, func(i, j int) bool { return opts[i].long < opts[j].long })
+	return opts
+}
+
+func findCompleteOpt(opts []completeOpt, flag string) (completeOpt, bool) {
+	for _, opt := range opts {
+		if opt.long == flag || opt.short == flag {
+			return opt, true
+		}
+	}
+	return completeOpt{}, false
+}
+
+// Complete returns the completion candidates of the last argument in args,
+// which are the CLI arguments without the program name, by the global and
+// command options and the sub-commands.
+//
+// The candidates of the option value come from the completer of the option
+// (see CompleterOpt), the choices of the validator (see ChoicesValidator),
+// or "true" and "false" for the bool option. If the value is a file path
+// (see FileHintOpt), the last line is the directive CompleteFileDirective.
+//
+// Notice: it will select the sub-commands in args.
+func (c *Config) Complete(args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	opts := c.getCompleteOpts()
+	last := args[len(args)-1]
+
+	var value *completeOpt
+	var positional bool
+	for _, arg := range args[:len(args)-1] {
+		switch {
+		case value != nil:
+			value = nil
+		case arg == "--":
+			return nil
+		case len(arg) > 1 && arg[0] == '-':
+			if strings.IndexByte(arg, '=') > -1 {
+				continue
+			}
+			if opt, ok := findCompleteOpt(opts, arg); ok && opt.takeValue() {
+				value = &opt
+			}
+		case !positional && c.SelectCommand(arg) != nil:
+			opts = c.getCompleteOpts()
+		default:
+			positional = true
+		}
+	}
+
+	if value != nil {
+		return completeValue(value.opt, "", last)
+	}
+
+	if strings.HasPrefix(last, "-") {
+		if index := strings.IndexByte(last, '='); index > -1 {
+			if opt, ok := findCompleteOpt(opts, last[:index]); ok {
+				return completeValue(opt.opt, last[:index+1], last[index+1:])
+			}
+			return nil
+		}
+
+		var cands []string
+		for _, opt := range opts {
+			for _, flag := range []string{opt.long, opt.short} {
+				if flag != "" && strings.HasPrefix(flag, last) {
+					cands = append(cands, flag)
+				}
+			}
+		}
+		return cands
+	}
+
+	var cands []string
+	if !positional {
+		for _, cmd := range c.Commands() {
+			if strings.HasPrefix(cmd.Name(), last) {
+				cands = append(cands, cmd.Name())
+			}
+		}
+	}
+	return cands
+}
+
+// completeValue returns the completion candidates of the value of the option,
+// each of which is prefixed by prefix, such as "--name=".
+func completeValue(opt Opt, prefix, value string) (cands []string) {
+	var values []string
+	if o, ok := opt.(CompleterOpt); ok {
+		values = o.Complete(value)
+	}
+
+	if len(values) == 0 {
+		if _, ok := opt.Zero().(bool); ok {
+			values = []string{"true", "false"}
+		} else if vc, ok := opt.(ValidatorChainOpt); ok {
+			for _, v := range vc.GetValidators() {
+				if cv, ok := v.(ChoicesValidator); ok {
+					values = append(values, cv.Choices()...)
+				}
+			}
+		}
+	}
+
+	for _, v := range values {
+		if strings.HasPrefix(v, value) {
+			cands = append(cands, prefix+v)
+		}
+	}
+
+	if o, ok := opt.(FileHintOpt); ok && prefix == "" {
+		if ok, exts := o.FileHint(); ok {
+			cands = append(cands, strings.TrimSpace(CompleteFileDirective+" "+strings.Join(exts, " ")))
+		}
+	}
+	return
+}
+
+func (c *Config) printCompletion(args []string) {
+	w := c.completionWriter
+	if w == nil {
+		w = os.Stdout
+	}
+
+	for _, line := range c.Complete(args) {
+		fmt.Fprintln(w, line)
+	}
+}
+
+var completionFuncRe = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// GenCompletion generates the completion script of the shell for the program,
+// and writes it into w. The shell is one of "bash", "zsh" and "fish".
+//
+// The script calls the program with the hidden command CompleteCommand to get
+// the candidates, so the dynamic completions are computed by the program itself,
+// and the program should call Parse to handle it. For example,
+//
+//    app completion bash > /etc/bash_completion.d/app
+//    app completion zsh > "${fpath[1]}/_app"
+//    app completion fish > ~/.config/fish/completions/app.fish
+func (c *Config) GenCompletion(shell string, w io.Writer) error {
+	tmpl, ok := completionTemplates[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell '%s'", shell)
+	}
+
+	prog := filepath.Base(os.Args[0])
+	return tmpl.Execute(w, map[string]string{
+		"Program":  prog,
+		"Function": "__" + completionFuncRe.ReplaceAllString(prog, "_") + "_complete",
+		"Command":  CompleteCommand,
+	})
+}
+
+var completionTemplates = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Parse(bashCompletion)),
+	"zsh":  template.Must(template.New("zsh").Parse(zshCompletion)),
+	"fish": template.Must(template.New("fish").Parse(fishCompletion)),
+}
+
+const bashCompletion = `# bash completion for {{.Program}}
+
+{{.Function}}() {
+    local line="${COMP_LINE:0:COMP_POINT}" words cur out exts ext
+    read -ra words <<< "$line"
+    if [[ "$line" == *" " ]]; then
+        words+=("")
+    fi
+    cur="${words[${#words[@]}-1]}"
+
+    COMPREPLY=()
+    out=$("${words[0]}" {{.Command}} "${words[@]:1}" 2>/dev/null)
+    while IFS= read -r line; do
+        case "$line" in
+            "") ;;
+            :file*)
+                exts="${line#:file}"
+                if [[ -z "$exts" ]]; then
+                    while IFS= read -r ext; do COMPREPLY+=("$ext"); done < <(compgen -f -- "$cur")
+                else
+                    while IFS= read -r ext; do COMPREPLY+=("$ext"); done < <(compgen -d -- "$cur")
+                    for ext in $exts; do
+                        while IFS= read -r line; do COMPREPLY+=("$line"); done < <(compgen -f -X "!*.$ext" -- "$cur")
+                    done
+                fi
+                compopt -o filenames 2>/dev/null
+                ;;
+            *) COMPREPLY+=("$line") ;;
+        esac
+    done <<< "$out"
+
+    # Bash splits the word by "=", so only complete the part after it.
+    if [[ "$cur" == *=* && "$COMP_WORDBREAKS" == *=* ]]; then
+        COMPREPLY=("${COMPREPLY[@]#*=}")
+    fi
+}
+
+complete -F {{.Function}} {{.Program}}
+`
+
+const zshCompletion = `#compdef {{.Program}}
+
+{{.Function}}() {
+    local -a lines cands
+    local line exts
+    lines=("${(@f)$(${words[1]} {{.Command}} "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    for line in $lines; do
+        case $line in
+            "") ;;
+            :file*)
+                exts=${line#:file}
+                exts=${exts# }
+                if [[ -z $exts ]]; then
+                    _files
+                else
+                    _files -g "*.(${exts// /|})"
+                fi
+                ;;
+            *) cands+=("$line") ;;
+        esac
+    done
+
+    if (( ${#cands} )); then
+        compadd -- "${cands[@]}"
+    fi
+}
+
+compdef {{.Function}} {{.Program}}
+`
+
+const fishCompletion = `# fish completion for {{.Program}}
+
+function {{.Function}}
+    set -l args (commandline -opc)
+    set -l prog $args[1]
+    set -e args[1]
+    set -l cur (commandline -ct)
+    for line in ($prog {{.Command}} $args $cur 2>/dev/null)
+        switch $line
+            case ''
+            case ':file*'
+                set -l exts (string split -n ' ' -- (string replace -r '^:file' '' -- $line))
+                if test (count $exts) -eq 0
+                    __fish_complete_path $cur
+                else
+                    for ext in $exts
+                        __fish_complete_suffix $cur .$ext
+                    end
+                end
+            case '*'
+                echo $line
+        end
+    end
+end
+
+complete -c {{.Program}} -f -a '({{.Function}})'
+`
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newCompletionConfig() *Config {
+	conf := NewConfig().AddParser(NewGetoptCliParser(true))
+	conf.RegisterCliOpts("", []Opt{
+		StrOpt("m", "mode", "dev", "").AddValidators(NewStrArrayValidator([]string{"dev", "prod"})),
+		BoolOpt("d", "debug", false, ""),
+		Str("config_file", "", "").(FileHintSetter).SetFileHint("ini", "conf"),
+	})
+
+	serve := conf.NewCommand("serve", "")
+	serve.RegisterCliOpt("", Str("user", "", "").(CompleterSetter).SetCompleter(func(prefix string) []string {
+		return []string{"admin", "guest"}
+	}))
+	conf.NewCommand("migrate", "")
+	return conf
+}
+
+func TestComplete(t *testing.T) {
+	cases := []struct {
+		args  []string
+		cands []string
+	}{
+		{[]string{""}, []string{"serve", "migrate"}},
+		{[]string{"s"}, []string{"serve"}},
+		{[]string{"--d"}, []string{"--debug"}},
+		{[]string{"--mode", "p"}, []string{"prod"}},
+		{[]string{"-m", ""}, []string{"dev", "prod"}},
+		{[]string{"--mode=d"}, []string{"--mode=dev"}},
+		{[]string{"--debug", ""}, []string{"serve", "migrate"}},
+		{[]string{"--debug="}, []string{"--debug=true", "--debug=false"}},
+		{[]string{"--config-file", ""}, []string{":file ini conf"}},
+		{[]string{"serve", "--u"}, []string{"--user"}},
+		{[]string{"serve", "--user", "a"}, []string{"admin"}},
+		{[]string{"serve", "arg", ""}, nil},
+		{[]string{"--", ""}, nil},
+	}
+
+	for _, c := range cases {
+		conf := newCompletionConfig()
+		if cands := conf.Complete(c.args); !reflect.DeepEqual(cands, c.cands) {
+			t.Errorf("%q: expect %q, but got %q", c.args, c.cands, cands)
+		}
+	}
+}
+
+func TestGenCompletion(t *testing.T) {
+	conf := newCompletionConfig()
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		buf := bytes.NewBuffer(nil)
+		if err := conf.GenCompletion(shell, buf); err != nil {
+			t.Error(err)
+		} else if !strings.Contains(buf.String(), CompleteCommand) {
+			t.Errorf("%s: no the complete command", shell)
+		}
+	}
+
+	if err := conf.GenCompletion("unknown", bytes.NewBuffer(nil)); err == nil {
+		t.Error("expect an error for the unknown shell")
+	}
+}
+
+func TestParseCompletion(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	conf := newCompletionConfig().SetCompletionWriter(buf)
+	if err := conf.Parse(CompleteCommand, "--mode", ""); err != ErrCompletion {
+		t.Errorf("expect ErrCompletion, but got %v", err)
+	} else if s := buf.String(); s != "dev\nprod\n" {
+		t.Errorf("unexpected completion output '%s'", s)
+	}
+}
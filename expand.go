@@ -0,0 +1,182 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// NoExpandOpt is an optional interface of Opt, which reports whether
+// the value of the option should not be expanded, because it legitimately
+// contains "$", such as a password or a template.
+type NoExpandOpt interface {
+	Opt
+
+	// NoExpand reports whether not to expand the value of the option.
+	NoExpand() bool
+}
+
+// EnableFileExpansion enables to expand the variables in the values from
+// the file parsers, such as the ini and property parser. See ExpandEnv.
+//
+// lookup is used to look up the value of the variable, which is os.LookupEnv
+// by default.
+//
+// If the option has implemented the interface NoExpandOpt and NoExpand returns
+// true, its value won't be expanded.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) EnableFileExpansion(lookup ...func(string) (string, bool)) *Config {
+	c.panicIsParsed(true)
+	c.expandLookup = os.LookupEnv
+	if len(lookup) > 0 && lookup[0] != nil {
+		c.expandLookup = lookup[0]
+	}
+	return c
+}
+
+// ExpandValue expands the variables in the value of the option named name
+// in the group if having enabled the file expansion. Or return value.
+//
+// The file parser should call it before setting the option value.
+func (c *Config) ExpandValue(group, name, value string) (string, error) {
+	if c.expandLookup == nil {
+		return value, nil
+	}
+
+	if g := c.getGroupByName(group, false); g != nil {
+		if opt, ok := g.opts[name]; ok {
+			if o, ok := opt.opt.(NoExpandOpt); ok && o.NoExpand() {
+				return value, nil
+			}
+		}
+	}
+
+	return ExpandEnv(value, c.expandLookup)
+}
+
+// expandFileEntry expands the value of the entry parsed from the config file.
+func (c *Config) expandFileEntry(e fileEntry) (string, error) {
+	value, err := c.ExpandValue(e.group, e.key, e.value)
+	if err != nil {
+		return "", fmt.Errorf("%s:%d: %s", e.file, e.line, err)
+	}
+	return value, nil
+}
+
+// ExpandEnv expands the POSIX-like variables in s by lookup, which supports
+//
+//    ${VAR}              // The value of VAR, or "" if VAR is not set.
+//    ${VAR:-default}     // The value of VAR, or default if VAR is not set or empty.
+//    ${VAR:?error}       // The value of VAR, or fail with error if VAR is not set or empty.
+//    $$                  // The escape of "$".
+//
+// The default and the error may contain the variables, too. The single "$"
+// not followed by "{" or "$" is kept literally.
+func ExpandEnv(s string, lookup func(string) (string, bool)) (string, error) {
+	if strings.IndexByte(s, '$') == -1 {
+		return s, nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			buf.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			buf.WriteByte('$')
+			i++
+		case '{':
+			end := matchBrace(s, i+1)
+			if end == -1 {
+				return "", fmt.Errorf("unterminated variable '%s'", s[i:])
+			}
+
+			value, err := expandVar(s[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(value)
+			i = end
+		default:
+			buf.WriteByte('$')
+		}
+	}
+	return buf.String(), nil
+}
+
+// matchBrace returns the index of "}" matching the "{" at start, or -1.
+func matchBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// expandVar expands the variable expression in "${}", such as "VAR",
+// "VAR:-default" or "VAR:?error".
+func expandVar(expr string, lookup func(string) (string, bool)) (string, error) {
+	name, op, word := expr, "", ""
+	if n := strings.Index(expr, ":"); n > -1 {
+		name, op, word = expr[:n], expr[n:], ""
+		if len(op) < 2 || (op[1] != '-' && op[1] != '?') {
+			return "", fmt.Errorf("invalid variable '${%s}'", expr)
+		}
+		op, word = op[:2], op[2:]
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("invalid variable '${%s}'", expr)
+	}
+	for _, r := range name {
+		if r != '_' && !(r >= '0' && r <= '9') && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') {
+			return "", fmt.Errorf("invalid variable name '%s'", name)
+		}
+	}
+
+	value, _ := lookup(name)
+	if value != "" || op == "" {
+		return value, nil
+	}
+
+	word, err := ExpandEnv(word, lookup)
+	if err != nil {
+		return "", err
+	}
+
+	if op == ":?" {
+		if word == "" {
+			word = "parameter null or not set"
+		}
+		return "", fmt.Errorf("%s: %s", name, word)
+	}
+	return word, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	envs := map[string]string{"HOST": "localhost", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := envs[name]
+		return v, ok
+	}
+
+	cases := []struct {
+		in, out string
+	}{
+		{"no variable", "no variable"},
+		{"${HOST}:80", "localhost:80"},
+		{"${UNSET}", ""},
+		{"${EMPTY:-default}", "default"},
+		{"${UNSET:-${HOST}}", "localhost"},
+		{"${HOST:-default}", "localhost"},
+		{"pa$$word", "pa$word"},
+		{"$HOST $", "$HOST $"},
+	}
+	for _, c := range cases {
+		if out, err := ExpandEnv(c.in, lookup); err != nil {
+			t.Errorf("%s: %s", c.in, err)
+		} else if out != c.out {
+			t.Errorf("%s: expect '%s', but got '%s'", c.in, c.out, out)
+		}
+	}
+
+	for _, s := range []string{"${UNSET:?must be set}", "${EMPTY:?}", "${HOST", "${}", "${A-B}"} {
+		if _, err := ExpandEnv(s, lookup); err == nil {
+			t.Errorf("%s: expect an error", s)
+		}
+	}
+}
+
+func TestFileExpansion(t *testing.T) {
+	file, err := ioutil.TempFile("", "go-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("[DEFAULT]\nurl = http://${HOST:-127.0.0.1}:${PORT}\npassword = pa$${HOST}\n")
+	file.Close()
+
+	lookup := func(name string) (string, bool) {
+		if name == "PORT" {
+			return "8080", true
+		}
+		return "", false
+	}
+
+	conf := NewConfig().AddParser(NewFlagCliParser(nil, true), NewSimpleIniParser("config-file"))
+	conf.EnableFileExpansion(lookup)
+	conf.RegisterOpts("", []Opt{Str("url", "", ""), Str("password", "", "").(NoExpandSetter).SetNoExpand(true)})
+	if err = conf.Parse("--config-file", file.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.String("url"); v != "http://127.0.0.1:8080" {
+		t.Errorf("expect '%s', but got '%s'", "http://127.0.0.1:8080", v)
+	}
+	if v := conf.String("password"); v != "pa$${HOST}" {
+		t.Errorf("expect '%s', but got '%s'", "pa$${HOST}", v)
+	}
+}
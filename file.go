@@ -0,0 +1,181 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// FileVerifier is used to verify the config file before it is parsed.
+type FileVerifier interface {
+	// Verify verifies the content, data, of the config file named filename,
+	// and returns the content to be parsed, from which the embedded signature,
+	// if having, should be removed.
+	//
+	// Return an error if the file is not trusted.
+	Verify(filename string, data []byte) ([]byte, error)
+}
+
+// SetFileVerifier sets the verifier of the config files, which will be used
+// by the file parsers, such as the ini and property parser.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) SetFileVerifier(verifier FileVerifier) *Config {
+	c.panicIsParsed(true)
+	c.verifier = verifier
+	return c
+}
+
+// EnableFileTemplate enables to render the config file as a Go text/template
+// before the file parsers decode it, which is disabled by default.
+//
+// The builtin template functions are
+//
+//    env NAME             // Return the value of the environment variable NAME.
+//    hostname             // Return the host name.
+//    file PATH            // Return the content of the file PATH without the trailing newlines.
+//    default DEFAULT VAL  // Return DEFAULT if VAL is empty, or VAL.
+//    required MSG VAL     // Return VAL, or fail with MSG if VAL is empty.
+//
+// For example,
+//
+//    [db]
+//    host = {{ env "DB_HOST" | default "127.0.0.1" }}
+//    password = {{ env "DB_PASSWORD" | required "DB_PASSWORD must be set" }}
+//
+// You can give some functions to add or override the builtin ones.
+//
+// If failing to render the template, ReadFile returns a TemplateError
+// with the line number of the config file.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) EnableFileTemplate(funcs ...template.FuncMap) *Config {
+	c.panicIsParsed(true)
+	c.tmplFuncs = template.FuncMap{
+		"env":      os.Getenv,
+		"hostname": os.Hostname,
+		"file":     tmplReadFile,
+		"default":  tmplDefault,
+		"required": tmplRequired,
+	}
+	for _, fm := range funcs {
+		for name, f := range fm {
+			c.tmplFuncs[name] = f
+		}
+	}
+	return c
+}
+
+func tmplReadFile(filename string) (string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func tmplDefault(_default string, value interface{}) interface{} {
+	if value == nil || IsZero(value) {
+		return _default
+	}
+	return value
+}
+
+func tmplRequired(msg string, value interface{}) (interface{}, error) {
+	if value == nil || IsZero(value) {
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return value, nil
+}
+
+// TemplateError stands for an error that the config file fails to be rendered
+// as the template.
+type TemplateError struct {
+	File string
+	Line int // 0 if the line is unknown.
+	Err  error
+}
+
+// Error implements the interface Error.
+func (e TemplateError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("the template of the config file '%s' failed at line %d: %v",
+			e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("the template of the config file '%s' failed: %v", e.File, e.Err)
+}
+
+// newTemplateError maps the error returned by text/template, the format of
+// which is like "template: NAME:LINE[:COLUMN]: MESSAGE", back to the line
+// of the config file.
+func newTemplateError(filename string, err error) TemplateError {
+	re := regexp.MustCompile(`^template: ` + regexp.QuoteMeta(filename) +
+		`:(\d+)(?::\d+)?: (?:executing "[^"]*" at <.*?>: )?(?:error calling \w+: )?(.*)$`)
+	if ms := re.FindStringSubmatch(err.Error()); len(ms) == 3 {
+		line, _ := strconv.Atoi(ms[1])
+		return TemplateError{File: filename, Line: line, Err: fmt.Errorf("%s", ms[2])}
+	}
+	return TemplateError{File: filename, Err: err}
+}
+
+func (c *Config) renderFile(filename string, data []byte) ([]byte, error) {
+	tmpl, err := template.New(filename).Funcs(c.tmplFuncs).Parse(string(data))
+	if err != nil {
+		return nil, newTemplateError(filename, err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = tmpl.Execute(buf, nil); err != nil {
+		return nil, newTemplateError(filename, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadFile reads the content of the config file named filename, verifies
+// it by the file verifier if having set it, then renders it as the template
+// if having enabled the file template.
+//
+// The file parser should use it to read the config file instead of reading it
+// by itself, so that the config file is handled by the same way.
+func (c *Config) ReadFile(filename string) (data []byte, err error) {
+	if data, err = ioutil.ReadFile(filename); err != nil {
+		return
+	}
+
+	if c.verifier != nil {
+		c.debug("Verifying the config file '%s'", filename)
+		if data, err = c.verifier.Verify(filename, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.tmplFuncs != nil {
+		c.debug("Rendering the config file '%s'", filename)
+		if data, err = c.renderFile(filename, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFileTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secret := filepath.Join(dir, "secret")
+	if err = ioutil.WriteFile(secret, []byte("hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("TMPL_DB_USER", "admin")
+	defer os.Unsetenv("TMPL_DB_USER")
+
+	filename := filepath.Join(dir, "app.ini")
+	data := "[db]\n" +
+		"user = {{ env \"TMPL_DB_USER\" | required \"TMPL_DB_USER must be set\" }}\n" +
+		"host = {{ env \"TMPL_DB_HOST\" | default \"127.0.0.1\" }}\n" +
+		"password = {{ file \"" + secret + "\" }}\n" +
+		"name = {{ upper \"app\" }}\n"
+	if err = ioutil.WriteFile(filename, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := NewConfig().AddParser(NewGetoptCliParser(true), NewSimpleIniParser("config-file"))
+	conf.EnableFileTemplate(template.FuncMap{"upper": strings.ToUpper})
+	conf.RegisterOpts("db", []Opt{Str("user", "", ""), Str("host", "", ""),
+		Str("password", "", ""), Str("name", "", "")})
+	if err = conf.Parse("--config-file", filename); err != nil {
+		t.Fatal(err)
+	}
+
+	db := conf.Group("db")
+	for name, expected := range map[string]string{
+		"user":     "admin",
+		"host":     "127.0.0.1",
+		"password": "hunter2",
+		"name":     "APP",
+	} {
+		if v := db.String(name); v != expected {
+			t.Errorf("expect [db]:[%s] '%s', but got '%s'", name, expected, v)
+		}
+	}
+}
+
+func TestFileTemplateError(t *testing.T) {
+	conf := NewConfig().EnableFileTemplate()
+	for _, test := range []struct {
+		data string
+		line int
+		msg  string
+	}{
+		{"a = 1\nb = {{ env \"TMPL_NONE\" | required \"TMPL_NONE must be set\" }}\n", 2,
+			"TMPL_NONE must be set"},
+		{"a = 1\nb = 2\nc = {{ nofunc }}\n", 3, `function "nofunc" not defined`},
+		{"a = 1\nb = {{ end }}\n", 2, ""},
+	} {
+		_, err := conf.renderFile("app.ini", []byte(test.data))
+		var terr TemplateError
+		if !errors.As(err, &terr) {
+			t.Errorf("expect a TemplateError, but got %v", err)
+		} else if terr.File != "app.ini" || terr.Line != test.line {
+			t.Errorf("expect the error at line %d, but got %d: %s", test.line, terr.Line, terr)
+		} else if test.msg != "" && terr.Err.Error() != test.msg {
+			t.Errorf("expect the error message '%s', but got '%s'", test.msg, terr.Err)
+		}
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	if v := tmplDefault("d", ""); v != "d" {
+		t.Errorf("expect '%s', but got '%v'", "d", v)
+	} else if v := tmplDefault("d", nil); v != "d" {
+		t.Errorf("expect '%s', but got '%v'", "d", v)
+	} else if v := tmplDefault("d", "v"); v != "v" {
+		t.Errorf("expect '%s', but got '%v'", "v", v)
+	}
+
+	if _, err := tmplRequired("missing", ""); err == nil || err.Error() != "missing" {
+		t.Errorf("expect the error 'missing', but got %v", err)
+	} else if v, err := tmplRequired("missing", 1); err != nil || v != 1 {
+		t.Errorf("expect 1, but got %v: %v", v, err)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CliParserOption is used to configure the CLI parser.
+type CliParserOption struct {
+	// If true, convert the underline in the option name to the hyphen.
+	UnderlineToHyphen bool
+
+	// If true, split the value of the slice or map option by the comma,
+	// such as "-host a,b -host c", which is ["a", "b", "c"].
+	// Or, each occurrence is an element, that's, ["a,b", "c"].
+	SplitComma bool
+}
+
+// optValue is the interface of flag.Value, which returns the value of the
+// specific type to set the option.
+type optValue interface {
+	flag.Value
+	optValue() interface{}
+}
+
+// sliceValue is the flag.Value of the slice option, which accumulates
+// the repeated occurrences.
+type sliceValue struct {
+	split  bool
+	values []string
+}
+
+func (v *sliceValue) String() string { return strings.Join(v.values, ",") }
+func (v *sliceValue) optValue() interface{} {
+	return append([]string(nil), v.values...) // Not to share the internal slice
+}
+func (v *sliceValue) Set(value string) error {
+	v.values = append(v.values, splitCliValue(value, v.split)...)
+	return nil
+}
+
+// mapValue is the flag.Value of the map option, which accumulates
+// the repeated occurrences like "-label k1=v1 -label k2=v2".
+type mapValue struct {
+	split  bool
+	values map[string]string
+}
+
+func (v *mapValue) optValue() interface{} {
+	values := make(map[string]string, len(v.values))
+	for key, value := range v.values {
+		values[key] = value
+	}
+	return values
+}
+
+func (v *mapValue) String() string {
+	kvs := make([]string, 0, len(v.values))
+	for key, value := range v.values {
+		kvs = append(kvs, fmt.Sprintf("%s=%s", key, value))
+	}
+	return strings.Join(kvs, ",")
+}
+
+func (v *mapValue) Set(value string) error {
+	if v.values == nil {
+		v.values = make(map[string]string, 4)
+	}
+
+	for _, kv := range splitCliValue(value, v.split) {
+		index := strings.IndexByte(kv, '=')
+		if index < 1 {
+			return fmt.Errorf("invalid key-value pair '%s'", kv)
+		}
+		v.values[kv[:index]] = kv[index+1:]
+	}
+	return nil
+}
+
+// counterValue is the flag.Value of the counter option, the value of which is
+// increased by each occurrence.
+type counterValue struct {
+	count int
+}
+
+func (v *counterValue) IsBoolFlag() bool      { return true }
+func (v *counterValue) String() string        { return strconv.Itoa(v.count) }
+func (v *counterValue) optValue() interface{} { return v.count }
+func (v *counterValue) Set(value string) (err error) {
+	switch value {
+	case "true":
+		v.count++
+	case "false":
+		v.count = 0
+	default:
+		v.count, err = strconv.Atoi(value)
+	}
+	return
+}
+
+func splitCliValue(value string, split bool) []string {
+	if !split {
+		return []string{value}
+	}
+
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// isMultiOpt reports whether the option is a slice or map option,
+// which may be given repeatedly on the CLI.
+func isMultiOpt(opt Opt) bool {
+	switch reflect.ValueOf(opt.Zero()).Kind() {
+	case reflect.Slice, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+// expandCounterArgs expands the short counter flags in args, such as "-vvv",
+// to "-verbose -verbose -verbose", which counters is the mapping from the one
+// character short name to the flag name.
+//
+// The value of the non-bool flag in fset, such as "-name -vv", and the rest
+// arguments from the first positional argument are not expanded.
+func expandCounterArgs(fset *flag.FlagSet, args []string, counters map[string]string) []string {
+	if len(counters) == 0 {
+		return args
+	}
+
+	_args := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			_args = append(_args, args[i:]...)
+			break
+		}
+
+		short := arg[1:2]
+		if name, ok := counters[short]; ok && arg[1:] == strings.Repeat(short, len(arg)-1) {
+			for j := 1; j < len(arg); j++ {
+				_args = append(_args, "-"+name)
+			}
+			continue
+		}
+		_args = append(_args, arg)
+
+		// The next argument is the value of the flag without "=".
+		name := strings.TrimPrefix(arg[1:], "-")
+		if strings.Contains(name, "=") || i+1 == len(args) {
+			continue
+		} else if fg := fset.Lookup(name); fg != nil && !isBoolFlag(fg.Value) {
+			i++
+			_args = append(_args, args[i])
+		}
+	}
+	return _args
+}
+
+func isBoolFlag(v flag.Value) bool {
+	b, ok := v.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
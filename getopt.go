@@ -0,0 +1,305 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetoptNegativePrefix is the prefix of the long bool option to set it to false,
+// such as "--no-debug".
+const GetoptNegativePrefix = "no-"
+
+type getoptOpt struct {
+	group string
+	long  string
+	short string
+	opt   Opt
+}
+
+// isFlag reports whether the option is a bool or counter option,
+// which requires no argument.
+func (o getoptOpt) isFlag() bool {
+	_, ok := o.opt.Zero().(bool)
+	return ok || IsCounterOpt(o.opt)
+}
+
+type getoptParser struct {
+	utoh  bool
+	split bool
+}
+
+// NewGetoptCliParser returns a new GNU/POSIX getopt-style CLI parser,
+// the priority of which is 0.
+//
+// It supports:
+//
+//    --name value, --name=value  // The long option.
+//    -n value, -nvalue, -n=value // The short option, which is Opt.Short().
+//    -abc                        // The bundling of the bool short options.
+//    --name, --no-name           // Set the bool option to true or false.
+//    --                          // Terminate the options.
+//
+// The positional arguments may be interspersed with the options, and all the
+// arguments after "--" are regarded as the positional arguments. The short
+// option is not prefixed by the group name.
+//
+// The leading positional arguments select the sub-commands, and the options
+// of the selected command may be given after it. See Config.SelectCommand.
+//
+// The slice and map options may be given repeatedly, such as
+// "--host a --host b" and "--label k1=v1 --label k2=v2", and each value is also
+// split by the comma. The int counter option is increased by each occurrence,
+// such as "-vvv" or "--verbose --verbose", and "--verbose=3" sets it directly.
+// See CountOpt.
+//
+// If underlineToHyphen is true, it will convert the underline to the hyphen.
+//
+// Notice: "-h" and "--help" print the usage by the help handler and return
+// ErrHelp, unless they have been registered as the options. And the version
+// option, such as "--version" or "--version=json", prints the version by
+// the version handler and returns ErrVersion. See SetVersionHandler.
+func NewGetoptCliParser(underlineToHyphen bool) Parser {
+	return NewGetoptCliParserWithOption(CliParserOption{
+		UnderlineToHyphen: underlineToHyphen,
+		SplitComma:        true,
+	})
+}
+
+// NewGetoptCliParserWithOption is the same as NewGetoptCliParser,
+// but configures the parser by the option.
+func NewGetoptCliParserWithOption(option CliParserOption) Parser {
+	return getoptParser{
+		utoh:  option.UnderlineToHyphen,
+		split: option.SplitComma,
+	}
+}
+
+func (p getoptParser) Name() string {
+	return "getopt"
+}
+
+func (p getoptParser) Priority() int {
+	return 0
+}
+
+func (p getoptParser) Pre(c *Config) error {
+	return nil
+}
+
+func (p getoptParser) cliStyle() cliStyle {
+	return cliStyle{prefix: "--", short: true, utoh: p.utoh}
+}
+
+func (p getoptParser) Post(c *Config) error {
+	return nil
+}
+
+func (p getoptParser) getOpts(c *Config) (longs, shorts map[string]getoptOpt, err error) {
+	longs = make(map[string]getoptOpt, 8)
+	shorts = make(map[string]getoptOpt, 8)
+	for _, group := range c.Groups() {
+		gname := group.FullName()
+		for _, opt := range group.CliOpts() {
+			name := cliOptName(c, gname, opt.Name(), p.utoh)
+			o := getoptOpt{group: gname, long: name, short: opt.Short(), opt: opt}
+			if _, ok := longs[name]; ok {
+				return nil, nil, fmt.Errorf("the option '--%s' is duplicated", name)
+			}
+			longs[name] = o
+
+			if o.short != "" {
+				if _, ok := shorts[o.short]; ok {
+					return nil, nil, fmt.Errorf("the short option '-%s' is duplicated", o.short)
+				}
+				shorts[o.short] = o
+			}
+		}
+	}
+	return
+}
+
+func (p getoptParser) Parse(c *Config) (err error) {
+	longs, shorts, err := p.getOpts(c)
+	if err != nil {
+		return
+	}
+
+	vname, _, _ := c.GetVersion()
+	args := c.CliArgs()
+	rests := make([]string, 0, len(args))
+	counters := make(map[string]int, 2)
+	slices := make(map[string][]string, 2)
+	multis := make(map[string]*mapValue, 2)
+	set := func(o getoptOpt, value string) error {
+		c.Printf("[%s] Parsing option '%s'", p.Name(), o.long)
+
+		var v interface{} = value
+		if IsCounterOpt(o.opt) {
+			cv := &counterValue{count: counters[o.long]}
+			if err := cv.Set(value); err != nil {
+				return fmt.Errorf("invalid value '%s' for option '%s': %s", value, o.long, err)
+			}
+			counters[o.long] = cv.count
+			v = cv.count
+		} else if _, ok := o.opt.Zero().(map[string]string); ok {
+			mv := multis[o.long]
+			if mv == nil {
+				mv = &mapValue{split: p.split}
+				multis[o.long] = mv
+			}
+			if err := mv.Set(value); err != nil {
+				return fmt.Errorf("invalid value '%s' for option '%s': %s", value, o.long, err)
+			}
+			v = mv.optValue()
+		} else if isMultiOpt(o.opt) {
+			values := append(slices[o.long], splitCliValue(value, p.split)...)
+			slices[o.long] = values
+			v = values
+		}
+
+		return c.SetOptValue(0, o.group, o.opt.Name(), v)
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			rests = append(rests, args[i+1:]...)
+			i = len(args)
+
+		case len(arg) < 2 || arg[0] != '-':
+			// Dispatch the sub-command by the leading positional argument.
+			if len(rests) == 0 && c.SelectCommand(arg) != nil {
+				if longs, shorts, err = p.getOpts(c); err != nil {
+					return
+				}
+				continue
+			}
+			rests = append(rests, arg)
+
+		case arg[1] == '-': // The long option
+			name, value, hasValue := arg[2:], "", false
+			if index := strings.IndexByte(name, '='); index > -1 {
+				name, value, hasValue = name[:index], name[index+1:], true
+			}
+
+			o, ok := longs[name]
+			if !ok {
+				if n := strings.TrimPrefix(name, GetoptNegativePrefix); n != name {
+					if o, ok = longs[n]; ok && o.isFlag() && !hasValue {
+						if err = set(o, "false"); err != nil {
+							return
+						}
+						continue
+					}
+				}
+
+				switch {
+				case vname != "" && name == vname:
+					return c.handleVersion(value)
+				case name == "help" && !hasValue:
+					if err = c.handleHelp(); err != nil {
+						return
+					}
+					return ErrHelp
+				}
+				return fmt.Errorf("unknown option '--%s'", name)
+			}
+
+			if !hasValue {
+				if o.isFlag() {
+					value = "true"
+				} else if i+1 < len(args) {
+					i++
+					value = args[i]
+				} else {
+					return fmt.Errorf("option '--%s' requires an argument", name)
+				}
+			}
+
+			if err = set(o, value); err != nil {
+				return
+			}
+
+		default: // The short option
+			name := arg[1:]
+			if index := strings.IndexByte(name, '='); index > -1 {
+				name = name[:index]
+			}
+
+			// The short name may contain more than one character.
+			if o, ok := shorts[name]; ok && len(name) > 1 {
+				value := strings.TrimPrefix(arg[1+len(name):], "=")
+				if value == "" && len(arg) == len(name)+1 {
+					if o.isFlag() {
+						value = "true"
+					} else if i+1 < len(args) {
+						i++
+						value = args[i]
+					} else {
+						return fmt.Errorf("option '-%s' requires an argument", name)
+					}
+				}
+
+				if err = set(o, value); err != nil {
+					return
+				}
+				continue
+			}
+
+			for j := 1; j < len(arg); j++ {
+				short := arg[j : j+1]
+				o, ok := shorts[short]
+				if !ok {
+					if short == "h" {
+						if err = c.handleHelp(); err != nil {
+							return
+						}
+						return ErrHelp
+					}
+					return fmt.Errorf("unknown option '-%s'", short)
+				}
+
+				if o.isFlag() {
+					if j+1 < len(arg) && arg[j+1] == '=' {
+						err = set(o, arg[j+2:])
+						j = len(arg)
+					} else {
+						err = set(o, "true")
+					}
+				} else if j+1 < len(arg) {
+					err = set(o, strings.TrimPrefix(arg[j+1:], "="))
+					j = len(arg)
+				} else if i+1 < len(args) {
+					i++
+					err = set(o, args[i])
+				} else {
+					err = fmt.Errorf("option '-%s' requires an argument", short)
+				}
+
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	c.SetArgs(rests)
+	return
+}
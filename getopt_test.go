@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetoptCliParser(t *testing.T) {
+	conf := NewConfig().AddParser(NewGetoptCliParser(true))
+	conf.RegisterCliOpts("", []Opt{
+		BoolOpt("a", "all", false, ""),
+		BoolOpt("v", "verbose", false, ""),
+		BoolOpt("", "color", true, ""),
+		StrOpt("o", "output", "", ""),
+		IntOpt("n", "num", 0, ""),
+		Str("log_file", "", ""),
+	})
+	conf.RegisterCliOpt("db", StrOpt("", "url", "", ""))
+
+	args := []string{"arg1", "-av", "--no-color", "-ofile", "arg2", "-n=3",
+		"--log-file", "/tmp/log", "--db.url=mysql://", "--", "-x", "--y"}
+	if err := conf.Parse(args...); err != nil {
+		t.Fatal(err)
+	}
+
+	if !conf.Bool("all") || !conf.Bool("verbose") || conf.Bool("color") {
+		t.Errorf("unexpected bool options: all=%v, verbose=%v, color=%v",
+			conf.Bool("all"), conf.Bool("verbose"), conf.Bool("color"))
+	}
+	if v := conf.String("output"); v != "file" {
+		t.Errorf("expect '%s', but got '%s'", "file", v)
+	}
+	if v := conf.Int("num"); v != 3 {
+		t.Errorf("expect %d, but got %d", 3, v)
+	}
+	if v := conf.String("log_file"); v != "/tmp/log" {
+		t.Errorf("expect '%s', but got '%s'", "/tmp/log", v)
+	}
+	if v := conf.Group("db").String("url"); v != "mysql://" {
+		t.Errorf("expect '%s', but got '%s'", "mysql://", v)
+	}
+	if rests := conf.Args(); !reflect.DeepEqual(rests, []string{"arg1", "arg2", "-x", "--y"}) {
+		t.Errorf("unexpected rest arguments %q", rests)
+	}
+
+	for _, args := range [][]string{{"--unknown"}, {"-ax"}, {"--output"}, {"-n"}} {
+		conf := NewConfig().AddParser(NewGetoptCliParser(false))
+		conf.RegisterCliOpts("", []Opt{BoolOpt("a", "all", false, ""), IntOpt("n", "num", 0, ""),
+			StrOpt("", "output", "", "")})
+		if err := conf.Parse(args...); err == nil {
+			t.Errorf("%q: expect an error", args)
+		}
+	}
+}
+
+func TestGetoptCliParserRepeatedAndCounter(t *testing.T) {
+	conf := NewConfig().AddParser(NewGetoptCliParser(false))
+	conf.RegisterCliOpts("", []Opt{
+		StringsOpt("H", "host", nil, ""),
+		StringMap("label", nil, ""),
+		CountOpt("v", "verbose", ""),
+		BoolOpt("d", "debug", false, ""),
+	})
+
+	args := []string{"-H", "a,b", "--host=c", "--label", "k1=v1", "--label=k2=v2", "-vdv", "--verbose"}
+	if err := conf.Parse(args...); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.Strings("host"); !reflect.DeepEqual(v, []string{"a", "b", "c"}) {
+		t.Errorf("unexpected hosts %q", v)
+	}
+	if v := conf.StringMap("label"); !reflect.DeepEqual(v, map[string]string{"k1": "v1", "k2": "v2"}) {
+		t.Errorf("unexpected labels %v", v)
+	}
+	if v := conf.Int("verbose"); v != 3 || !conf.Bool("debug") {
+		t.Errorf("verbose=%d, debug=%v", v, conf.Bool("debug"))
+	}
+}
@@ -1,3 +1,11 @@
 module github.com/xgfone/go-config
 
-require github.com/xgfone/go-tools v5.5.2+incompatible
+go 1.18
+
+require (
+	github.com/spf13/pflag v1.0.5
+	github.com/xgfone/go-tools v5.5.2+incompatible
+	golang.org/x/term v0.10.0
+)
+
+require golang.org/x/sys v0.10.0 // indirect
@@ -19,8 +19,10 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -31,18 +33,69 @@ type option struct {
 	opt   Opt
 	prio  int
 	isCli bool
+	cmd   *Command
+	field reflect.Value
+
+	// layers is the values from all the sources in the ascending order
+	// of the priority, the first of which is the current value.
+	layers []Layer
+}
+
+// setLayer adds the layer, or replaces the layer from the same source and
+// file at the same priority, and returns its index in the layer stack.
+func (o *option) setLayer(layer Layer) (index int) {
+	// Remove the old layer from the same source and file at the same priority,
+	// so the base file and its profile files are kept as the different layers.
+	for i := range o.layers {
+		if o.layers[i].Priority == layer.Priority && o.layers[i].Source == layer.Source &&
+			o.layers[i].Origin.File == layer.Origin.File && o.layers[i].Runtime == layer.Runtime {
+			o.layers = append(o.layers[:i], o.layers[i+1:]...)
+			break
+		}
+	}
+
+	// The later layer is placed before the others at the same priority,
+	// but the layer from the parser is placed after those set at runtime.
+	index = sort.Search(len(o.layers), func(i int) bool {
+		if o.layers[i].Priority == layer.Priority && !layer.Runtime {
+			return !o.layers[i].Runtime
+		}
+		return o.layers[i].Priority >= layer.Priority
+	})
+
+	o.layers = append(o.layers, Layer{})
+	copy(o.layers[index+1:], o.layers[index:])
+	o.layers[index] = layer
+	return
+}
+
+// unsetLayer removes all the layers with the priority, and reports whether
+// the current layer is removed.
+func (o *option) unsetLayer(priority int) (current bool) {
+	layers := o.layers[:0]
+	for i, layer := range o.layers {
+		if layer.Priority == priority {
+			current = current || i == 0
+			continue
+		}
+		layers = append(layers, layer)
+	}
+	o.layers = layers
+	return
 }
 
 // OptGroup is the group of the option.
 type OptGroup struct {
+	valueGetter
+
 	conf *Config
 	lock sync.RWMutex
 
 	fname  string
 	name   string
 	opts   map[string]*option
+	alts   map[string][]*option // The options with the same name of the commands
 	values map[string]interface{}
-	fields map[string]reflect.Value
 }
 
 // NewOptGroup returns a new OptGroup.
@@ -55,16 +108,18 @@ func newOptGroup(name, fullName string, conf *Config) *OptGroup {
 		panic(fmt.Errorf("Config is nil"))
 	}
 
-	return &OptGroup{
+	g := &OptGroup{
 		conf:  conf,
 		name:  name,
 		fname: fullName,
 		lock:  sync.RWMutex{},
 
 		opts:   make(map[string]*option, 8),
+		alts:   make(map[string][]*option),
 		values: make(map[string]interface{}, 8),
-		fields: make(map[string]reflect.Value),
 	}
+	g.valueGetter = valueGetter{group: name, value: g.Value}
+	return g
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -96,19 +151,25 @@ func (g *OptGroup) Priority(name string) int {
 }
 
 // AllOpts returns all the registered options, including the CLI options.
+//
+// Notice: the options of the commands that are not selected are excluded.
 func (g *OptGroup) AllOpts() []Opt {
 	opts := make([]Opt, 0, len(g.opts))
 	for _, opt := range g.opts {
-		opts = append(opts, opt.opt)
+		if g.conf.isActiveCommand(opt.cmd) {
+			opts = append(opts, opt.opt)
+		}
 	}
 	return opts
 }
 
 // Opts returns all the registered options, except the CLI options.
+//
+// Notice: the options of the commands that are not selected are excluded.
 func (g *OptGroup) Opts() []Opt {
 	opts := make([]Opt, 0, len(g.opts))
 	for _, opt := range g.opts {
-		if !opt.isCli {
+		if !opt.isCli && g.conf.isActiveCommand(opt.cmd) {
 			opts = append(opts, opt.opt)
 		}
 	}
@@ -116,16 +177,45 @@ func (g *OptGroup) Opts() []Opt {
 }
 
 // CliOpts returns all the registered CLI options, except the non-CLI options.
+//
+// Notice: the options of the commands that are not selected are excluded.
 func (g *OptGroup) CliOpts() []Opt {
 	opts := make([]Opt, 0, len(g.opts))
 	for _, opt := range g.opts {
-		if opt.isCli {
+		if opt.isCli && g.conf.isActiveCommand(opt.cmd) {
 			opts = append(opts, opt.opt)
 		}
 	}
 	return opts
 }
 
+// hasActiveOpts reports whether the group has the options of the selected
+// command path or the global options.
+func (g *OptGroup) hasActiveOpts() bool {
+	for _, opt := range g.opts {
+		if g.conf.isActiveCommand(opt.cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectCommand switches the options with the same name to those
+// of the selected command path.
+func (g *OptGroup) selectCommand() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for name, alts := range g.alts {
+		for _, opt := range alts {
+			if g.conf.isActiveCommand(opt.cmd) {
+				g.opts[name] = opt
+				break
+			}
+		}
+	}
+}
+
 // HasOpt reports whether the group contains the option named 'name'.
 func (g *OptGroup) HasOpt(name string) bool {
 	_, ok := g.opts[name]
@@ -190,48 +280,143 @@ func (g *OptGroup) parseOptValue(name string, value interface{}) (interface{}, e
 	return value, nil
 }
 
-func (g *OptGroup) _setOptValue(priority int, name string, value interface{}) (ok bool) {
-	func() {
-		g.lock.Lock()
-		defer g.lock.Unlock()
-
-		opt := g.opts[name]
-		if priority > opt.prio {
-			g.conf.debug("Ignore the option [%s]:[%s]: %d > %d", g.name, name, priority, opt.prio)
-			return
-		}
-		opt.prio = priority
-		ok = true
+// _setOptValue sets the value of the option named name in the layer.
+//
+// After parsing, the state validators are called against the state
+// with the new value before applying it, and it is serialized with
+// the transactions. See RegisterStateValidator.
+//
+// If secret is true or the option is sensitive, the value will not be output
+// into the debug log.
+func (g *OptGroup) _setOptValue(layer Layer, name string, secret bool) (err error) {
+	c := g.conf
+	c.txnLock.Lock()
+	if layer.Priority <= g.Priority(name) {
+		err = c.validateChange(Change{Group: g.name, Name: name, New: layer.Value})
+	}
+	if err != nil {
+		c.txnLock.Unlock()
+		return
+	}
 
-		g.values[name] = value
-		if field, ok := g.fields[name]; ok {
-			field.Set(reflect.ValueOf(value))
-		}
-	}()
+	g.lock.Lock()
+	old, ok := g.applyLayer(layer, name)
+	g.lock.Unlock()
+	if ok {
+		c.countParserValue()
+		c.updateSnapshot()
+	}
+	c.txnLock.Unlock()
 
 	if ok {
-		g.conf.debug("Set [%s]:[%s] to [%v]", g.name, name, value)
-		if g.conf.watch != nil {
-			g.conf.watch(g.name, name, value)
+		g.logOptValue(layer, name, secret)
+		if c.watch != nil {
+			c.watch(g.name, name, layer.Value)
 		}
+		c.emitChanges([]Change{{Group: g.name, Name: name, Old: old, New: layer.Value}})
+	}
+
+	return
+}
+
+// validateChange calls the state validators against the state with the change
+// of the current value after parsing, which must be called with the transaction
+// lock.
+func (c *Config) validateChange(change Change) error {
+	if !c.parsed || atomic.LoadInt32(&c.snapPaused) != 0 || len(c.stateValidators) == 0 {
+		return nil // Validate the complete state only once when parsing.
+	}
+	return c.validateState(c.buildSnapshot(0).withChanges([]Change{change}))
+}
+
+// applyLayer sets the layer of the option named name, and returns the old
+// value and whether the current value is changed, which must be called
+// with the lock.
+func (g *OptGroup) applyLayer(layer Layer, name string) (old interface{}, ok bool) {
+	opt := g.opts[name]
+	if opt.setLayer(layer) > 0 {
+		g.conf.debug("Keep the option [%s]:[%s] in the layer %d under %d",
+			g.name, name, layer.Priority, opt.prio)
+		return
 	}
+	opt.prio = layer.Priority
+	old, ok = g.values[name], true
 
+	g.values[name] = layer.Value
+	if opt.field.IsValid() {
+		opt.field.Set(reflect.ValueOf(layer.Value))
+	}
 	return
 }
 
-func (g *OptGroup) setOptValue(priority int, name string, value interface{}) (err error) {
-	if value, err = g.parseOptValue(name, value); err == nil {
-		g._setOptValue(priority, name, value)
+// logOptValue outputs the debug log of the option value which has been set.
+func (g *OptGroup) logOptValue(layer Layer, name string, secret bool) {
+	if msg := GetDeprecated(g.opts[name].opt); msg != "" && layer.Priority < 1000 && !g.conf.reloading {
+		fmt.Fprintf(g.conf.getUsageWriter(), "WARNING: the option [%s]:[%s] is deprecated: %s\n",
+			g.name, name, msg)
+	}
+
+	if secret || IsSensitiveOpt(g.opts[name].opt) {
+		g.conf.debug("Set [%s]:[%s] to [******]", g.name, name)
+	} else {
+		g.conf.debug("Set [%s]:[%s] to [%v]", g.name, name, layer.Value)
+	}
+}
+
+func (g *OptGroup) setOptValue(priority int, name string, value interface{}, secret bool) error {
+	source, _ := g.conf.getLayerSource(priority)
+	layer := Layer{Priority: priority, Source: source, Value: value}
+	return g.setLayerValue(layer, name, secret)
+}
+
+// setLayerValue parses and validates the value of the layer, then sets it.
+func (g *OptGroup) setLayerValue(layer Layer, name string, secret bool) (err error) {
+	if opt, ok := g.opts[name]; ok && !g.conf.isActiveCommand(opt.cmd) {
+		g.conf.debug("Ignore the option [%s]:[%s] of the command '%s'", g.name, name,
+			opt.cmd.FullName())
+		return nil
+	}
+
+	if layer.Value, err = g.parseOptValue(name, layer.Value); err == nil {
+		layer.Time = time.Now()
+		layer.Secret = layer.Secret || secret
+		err = g._setOptValue(layer, name, secret)
 	}
 	return
 }
 
 // Check whether the required option has no value or a ZORE value.
+//
+// If prompting is enabled, it prompts for the value of the option
+// instead of returning an error. See Config.EnablePrompt.
 func (g *OptGroup) checkRequiredOption() (err error) {
-	for name, opt := range g.opts {
+	names := make([]string, 0, len(g.opts))
+	for name := range g.opts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		opt := g.opts[name]
+		if !g.conf.isActiveCommand(opt.cmd) {
+			continue
+		}
+
 		if _, ok := g.values[name]; !ok {
+			if IsRequiredOpt(opt.opt) {
+				if g.conf.canPrompt() {
+					if err = g.promptOptValue(name, opt.opt); err != nil {
+						return
+					}
+					continue
+				}
+
+				return fmt.Errorf("the required option '%s' in the group '%s' has no value",
+					name, g.name)
+			}
+
 			if v := opt.opt.Default(); v != nil {
-				if err = g.setOptValue(1000, name, v); err != nil {
+				if err = g.setOptValue(1000, name, v, false); err != nil {
 					return
 				}
 				continue
@@ -239,7 +424,7 @@ func (g *OptGroup) checkRequiredOption() (err error) {
 
 			if g.conf.isZero {
 				if v := opt.opt.Zero(); v != nil {
-					if err = g.setOptValue(1000, name, opt.opt.Zero()); err != nil {
+					if err = g.setOptValue(1000, name, opt.opt.Zero(), false); err != nil {
 						return
 					}
 					continue
@@ -247,9 +432,27 @@ func (g *OptGroup) checkRequiredOption() (err error) {
 			}
 
 			if g.conf.isRequired {
+				if g.conf.canPrompt() {
+					if err = g.promptOptValue(name, opt.opt); err != nil {
+						return
+					}
+					continue
+				}
+
 				return fmt.Errorf("the option '%s' in the group '%s' has no value",
 					name, g.name)
 			}
+		} else if v := opt.opt.Default(); v != nil && opt.prio < 1000 {
+			// Keep the default value as the bottom layer without changing
+			// the current value, which is revealed when the values of all
+			// the other layers are unset. But ignore the invalid default value.
+			if v, e := g.parseOptValue(name, v); e != nil {
+				g.conf.debug("Ignore the default of [%s]:[%s]: %s", g.name, name, e)
+			} else {
+				g.lock.Lock()
+				opt.setLayer(Layer{Priority: 1000, Source: LayerDefault, Value: v, Time: time.Now()})
+				g.lock.Unlock()
+			}
 		}
 	}
 	return nil
@@ -258,7 +461,7 @@ func (g *OptGroup) checkRequiredOption() (err error) {
 //////////////////////////////////////////////////////////////////////////////
 /// Register Options
 
-func (g *OptGroup) registerStruct(s interface{}, cli bool) {
+func (g *OptGroup) registerStruct(cmd *Command, s interface{}, cli bool) {
 	sv := reflect.ValueOf(s)
 	if sv.IsNil() || !sv.IsValid() {
 		panic(fmt.Errorf("the struct is invalid or can't be set"))
@@ -272,10 +475,10 @@ func (g *OptGroup) registerStruct(s interface{}, cli bool) {
 		panic(fmt.Errorf("the struct is not a struct"))
 	}
 
-	g.registerStructByValue(g.name, sv, cli)
+	g.registerStructByValue(cmd, g.name, sv, cli)
 }
 
-func (g *OptGroup) registerStructByValue(parent string, sv reflect.Value, cli bool) {
+func (g *OptGroup) registerStructByValue(cmd *Command, parent string, sv reflect.Value, cli bool) {
 	if sv.Kind() == reflect.Ptr {
 		sv = sv.Elem()
 	}
@@ -300,17 +503,7 @@ func (g *OptGroup) registerStructByValue(parent string, sv reflect.Value, cli bo
 			name = tagname
 		}
 
-		isCli := cli
-		if _cli := strings.TrimSpace(field.Tag.Get("cli")); _cli != "" {
-			switch _cli {
-			case "1", "t", "T", "on", "On", "ON", "true", "True", "TRUE":
-				isCli = true
-			case "0", "f", "F", "off", "Off", "OFF", "false", "False", "FALSE":
-				isCli = false
-			default:
-				panic(fmt.Errorf("no support '%s' for cli", field.Tag.Get("cli")))
-			}
-		}
+		isCli := parseBoolTag(field, "cli", cli)
 
 		gname := g.name
 		taggroup, resetgroup := field.Tag.Lookup("group")
@@ -333,7 +526,7 @@ func (g *OptGroup) registerStructByValue(parent string, sv reflect.Value, cli bo
 					}
 				}
 
-				g.conf.getGroupByName(parentGroup, true).registerStructByValue(parentGroup, fieldV, isCli)
+				g.conf.getGroupByName(parentGroup, true).registerStructByValue(cmd, parentGroup, fieldV, isCli)
 				continue
 			}
 		}
@@ -362,30 +555,100 @@ func (g *OptGroup) registerStructByValue(parent string, sv reflect.Value, cli bo
 		}
 
 		opt := newBaseOpt(short, name, _default, help, _type)
+
+		// Get whether to expand the value from the tag "expand".
+		opt.noExpand = !parseBoolTag(field, "expand", true)
+
+		// Get whether the option is required, deprecated or sensitive
+		// from the tags "required", "deprecated" and "sensitive".
+		opt.required = parseBoolTag(field, "required", false)
+		opt.deprecated = strings.TrimSpace(field.Tag.Get("deprecated"))
+		opt.sensitive = parseBoolTag(field, "sensitive", false)
+
+		// Get the names of the environment variables from the tag "env",
+		// which are separated by the comma.
+		if env := strings.TrimSpace(field.Tag.Get("env")); env != "" {
+			var envs []string
+			for _, e := range strings.Split(env, ",") {
+				if e = strings.TrimSpace(e); e != "" {
+					envs = append(envs, e)
+				}
+			}
+			opt.envs = envs
+		}
+
 		group := g.conf.getGroupByName(gname, true)
-		group.registerOpt(isCli, opt)
-		group.fields[name] = fieldV
+		if o := group.registerOpt(cmd, isCli, opt); o != nil {
+			o.field = fieldV
+		}
+	}
+}
+
+// parseBoolTag parses the bool value of the tag of the field,
+// or returns _default if the tag does not exist.
+func parseBoolTag(field reflect.StructField, tag string, _default bool) bool {
+	switch v := strings.TrimSpace(field.Tag.Get(tag)); v {
+	case "":
+		return _default
+	case "1", "t", "T", "on", "On", "ON", "true", "True", "TRUE":
+		return true
+	case "0", "f", "F", "off", "Off", "OFF", "false", "False", "FALSE":
+		return false
+	default:
+		panic(fmt.Errorf("no support '%s' for %s", v, tag))
 	}
 }
 
 // registerOpt registers the option into the group.
 //
-// The first argument, cli, indicates whether the option is as the CLI option,
+// The first argument, cmd, is the command which the option belongs to,
+// and nil represents the global option.
+//
+// The second argument, cli, indicates whether the option is as the CLI option,
 // too.
-func (g *OptGroup) registerOpt(cli bool, opt Opt) {
+//
+// The options with the same name may be registered by the different commands
+// that are not on the same command path, and only the option of the selected
+// command takes effect.
+//
+// Return nil if the option has not been registered.
+func (g *OptGroup) registerOpt(cmd *Command, cli bool, opt Opt) *option {
 	if opt == nil {
-		return
+		return nil
 	}
 
-	if _, ok := g.opts[opt.Name()]; ok {
-		if g.conf.isPanic {
-			panic(fmt.Errorf("the option '%s' has been registered into the group '%s'", opt.Name(), g.name))
+	name := opt.Name()
+	o := &option{isCli: cli, opt: opt, prio: 1 << 31, cmd: cmd}
+	if old, ok := g.opts[name]; ok {
+		alts := g.alts[name]
+		if len(alts) == 0 {
+			alts = []*option{old}
 		}
-		g.conf.debug("WARNING: Ingore to reregister group=%s, name=%s, cli=%t", g.name, opt.Name(), cli)
-		return
+
+		for _, alt := range alts {
+			if !cmd.isExclusive(alt.cmd) {
+				if g.conf.isPanic {
+					panic(fmt.Errorf("the option '%s' has been registered into the group '%s'", name, g.name))
+				}
+				g.conf.debug("WARNING: Ingore to reregister group=%s, name=%s, cli=%t", g.name, name, cli)
+				return nil
+			}
+		}
+
+		g.alts[name] = append(alts, o)
+		if g.conf.isActiveCommand(cmd) {
+			g.opts[name] = o
+		}
+	} else {
+		g.opts[name] = o
+	}
+
+	if cmd == nil {
+		g.conf.debug("Register group=%s, name=%s, cli=%t", g.name, name, cli)
+	} else {
+		g.conf.debug("Register group=%s, name=%s, cli=%t, command=%s", g.name, name, cli, cmd.FullName())
 	}
-	g.opts[opt.Name()] = &option{isCli: cli, opt: opt, prio: 1 << 31}
-	g.conf.debug("Register group=%s, name=%s, cli=%t", g.name, opt.Name(), cli)
+	return o
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -406,10 +669,22 @@ func (g *OptGroup) V(name string) interface{} {
 	return g.Value(name)
 }
 
-func (g *OptGroup) getValue(name string, _type optType) (interface{}, error) {
-	opt := g.Value(name)
+// valueGetter provides the typed getters of the option values in a group,
+// which is shared by OptGroup and SnapshotGroup.
+type valueGetter struct {
+	group string                        // The name of the group.
+	value func(name string) interface{} // Return the value of the option.
+}
+
+func (g valueGetter) getValue(name string, _type optType) (interface{}, error) {
+	return getTypedValue(g.group, name, g.value(name), _type)
+}
+
+// getTypedValue returns the value of the option named name in the group
+// if its type is _type, or an error.
+func getTypedValue(group, name string, opt interface{}, _type optType) (interface{}, error) {
 	if opt == nil {
-		return nil, fmt.Errorf("the group '%s' has no option '%s'", g.name, name)
+		return nil, fmt.Errorf("the group '%s' has no option '%s'", group, name)
 	}
 
 	switch _type {
@@ -509,17 +784,21 @@ func (g *OptGroup) getValue(name string, _type optType) (interface{}, error) {
 		if v, ok := opt.([]time.Time); ok {
 			return v, nil
 		}
+	case stringMapType:
+		if v, ok := opt.(map[string]string); ok {
+			return v, nil
+		}
 	default:
 		return nil, fmt.Errorf("don't support the type '%s'", _type)
 	}
 	return nil, fmt.Errorf("the option '%s' in the group '%s' is not the type '%s'",
-		name, g.name, _type)
+		name, group, _type)
 }
 
 // BoolE returns the option value, the type of which is bool.
 //
 // Return an error if no the option or the type of the option isn't bool.
-func (g *OptGroup) BoolE(name string) (bool, error) {
+func (g valueGetter) BoolE(name string) (bool, error) {
 	v, err := g.getValue(name, boolType)
 	if err != nil {
 		return false, err
@@ -528,7 +807,7 @@ func (g *OptGroup) BoolE(name string) (bool, error) {
 }
 
 // BoolD is the same as BoolE, but returns the default if there is an error.
-func (g *OptGroup) BoolD(name string, _default bool) bool {
+func (g valueGetter) BoolD(name string, _default bool) bool {
 	if value, err := g.BoolE(name); err == nil {
 		return value
 	}
@@ -536,7 +815,7 @@ func (g *OptGroup) BoolD(name string, _default bool) bool {
 }
 
 // Bool is the same as BoolE, but panic if there is an error.
-func (g *OptGroup) Bool(name string) bool {
+func (g valueGetter) Bool(name string) bool {
 	value, err := g.BoolE(name)
 	if err != nil {
 		panic(err)
@@ -547,7 +826,7 @@ func (g *OptGroup) Bool(name string) bool {
 // StringE returns the option value, the type of which is string.
 //
 // Return an error if no the option or the type of the option isn't string.
-func (g *OptGroup) StringE(name string) (string, error) {
+func (g valueGetter) StringE(name string) (string, error) {
 	v, err := g.getValue(name, stringType)
 	if err != nil {
 		return "", err
@@ -556,7 +835,7 @@ func (g *OptGroup) StringE(name string) (string, error) {
 }
 
 // StringD is the same as StringE, but returns the default if there is an error.
-func (g *OptGroup) StringD(name, _default string) string {
+func (g valueGetter) StringD(name, _default string) string {
 	if value, err := g.StringE(name); err == nil {
 		return value
 	}
@@ -564,7 +843,7 @@ func (g *OptGroup) StringD(name, _default string) string {
 }
 
 // String is the same as StringE, but panic if there is an error.
-func (g *OptGroup) String(name string) string {
+func (g valueGetter) String(name string) string {
 	value, err := g.StringE(name)
 	if err != nil {
 		panic(err)
@@ -575,7 +854,7 @@ func (g *OptGroup) String(name string) string {
 // IntE returns the option value, the type of which is int.
 //
 // Return an error if no the option or the type of the option isn't int.
-func (g *OptGroup) IntE(name string) (int, error) {
+func (g valueGetter) IntE(name string) (int, error) {
 	v, err := g.getValue(name, intType)
 	if err != nil {
 		return 0, err
@@ -584,7 +863,7 @@ func (g *OptGroup) IntE(name string) (int, error) {
 }
 
 // IntD is the same as IntE, but returns the default if there is an error.
-func (g *OptGroup) IntD(name string, _default int) int {
+func (g valueGetter) IntD(name string, _default int) int {
 	if value, err := g.IntE(name); err == nil {
 		return value
 	}
@@ -592,7 +871,7 @@ func (g *OptGroup) IntD(name string, _default int) int {
 }
 
 // Int is the same as IntE, but panic if there is an error.
-func (g *OptGroup) Int(name string) int {
+func (g valueGetter) Int(name string) int {
 	value, err := g.IntE(name)
 	if err != nil {
 		panic(err)
@@ -603,7 +882,7 @@ func (g *OptGroup) Int(name string) int {
 // Int8E returns the option value, the type of which is int8.
 //
 // Return an error if no the option or the type of the option isn't int8.
-func (g *OptGroup) Int8E(name string) (int8, error) {
+func (g valueGetter) Int8E(name string) (int8, error) {
 	v, err := g.getValue(name, int8Type)
 	if err != nil {
 		return 0, err
@@ -612,7 +891,7 @@ func (g *OptGroup) Int8E(name string) (int8, error) {
 }
 
 // Int8D is the same as Int8E, but returns the default if there is an error.
-func (g *OptGroup) Int8D(name string, _default int8) int8 {
+func (g valueGetter) Int8D(name string, _default int8) int8 {
 	if value, err := g.Int8E(name); err == nil {
 		return value
 	}
@@ -620,7 +899,7 @@ func (g *OptGroup) Int8D(name string, _default int8) int8 {
 }
 
 // Int8 is the same as Int8E, but panic if there is an error.
-func (g *OptGroup) Int8(name string) int8 {
+func (g valueGetter) Int8(name string) int8 {
 	value, err := g.Int8E(name)
 	if err != nil {
 		panic(err)
@@ -631,7 +910,7 @@ func (g *OptGroup) Int8(name string) int8 {
 // Int16E returns the option value, the type of which is int16.
 //
 // Return an error if no the option or the type of the option isn't int16.
-func (g *OptGroup) Int16E(name string) (int16, error) {
+func (g valueGetter) Int16E(name string) (int16, error) {
 	v, err := g.getValue(name, int16Type)
 	if err != nil {
 		return 0, err
@@ -640,7 +919,7 @@ func (g *OptGroup) Int16E(name string) (int16, error) {
 }
 
 // Int16D is the same as Int16E, but returns the default if there is an error.
-func (g *OptGroup) Int16D(name string, _default int16) int16 {
+func (g valueGetter) Int16D(name string, _default int16) int16 {
 	if value, err := g.Int16E(name); err == nil {
 		return value
 	}
@@ -648,7 +927,7 @@ func (g *OptGroup) Int16D(name string, _default int16) int16 {
 }
 
 // Int16 is the same as Int16E, but panic if there is an error.
-func (g *OptGroup) Int16(name string) int16 {
+func (g valueGetter) Int16(name string) int16 {
 	value, err := g.Int16E(name)
 	if err != nil {
 		panic(err)
@@ -659,7 +938,7 @@ func (g *OptGroup) Int16(name string) int16 {
 // Int32E returns the option value, the type of which is int32.
 //
 // Return an error if no the option or the type of the option isn't int32.
-func (g *OptGroup) Int32E(name string) (int32, error) {
+func (g valueGetter) Int32E(name string) (int32, error) {
 	v, err := g.getValue(name, int32Type)
 	if err != nil {
 		return 0, err
@@ -668,7 +947,7 @@ func (g *OptGroup) Int32E(name string) (int32, error) {
 }
 
 // Int32D is the same as Int32E, but returns the default if there is an error.
-func (g *OptGroup) Int32D(name string, _default int32) int32 {
+func (g valueGetter) Int32D(name string, _default int32) int32 {
 	if value, err := g.Int32E(name); err == nil {
 		return value
 	}
@@ -676,7 +955,7 @@ func (g *OptGroup) Int32D(name string, _default int32) int32 {
 }
 
 // Int32 is the same as Int32E, but panic if there is an error.
-func (g *OptGroup) Int32(name string) int32 {
+func (g valueGetter) Int32(name string) int32 {
 	value, err := g.Int32E(name)
 	if err != nil {
 		panic(err)
@@ -687,7 +966,7 @@ func (g *OptGroup) Int32(name string) int32 {
 // Int64E returns the option value, the type of which is int64.
 //
 // Return an error if no the option or the type of the option isn't int64.
-func (g *OptGroup) Int64E(name string) (int64, error) {
+func (g valueGetter) Int64E(name string) (int64, error) {
 	v, err := g.getValue(name, int64Type)
 	if err != nil {
 		return 0, err
@@ -696,7 +975,7 @@ func (g *OptGroup) Int64E(name string) (int64, error) {
 }
 
 // Int64D is the same as Int64E, but returns the default if there is an error.
-func (g *OptGroup) Int64D(name string, _default int64) int64 {
+func (g valueGetter) Int64D(name string, _default int64) int64 {
 	if value, err := g.Int64E(name); err == nil {
 		return value
 	}
@@ -704,7 +983,7 @@ func (g *OptGroup) Int64D(name string, _default int64) int64 {
 }
 
 // Int64 is the same as Int64E, but panic if there is an error.
-func (g *OptGroup) Int64(name string) int64 {
+func (g valueGetter) Int64(name string) int64 {
 	value, err := g.Int64E(name)
 	if err != nil {
 		panic(err)
@@ -715,7 +994,7 @@ func (g *OptGroup) Int64(name string) int64 {
 // UintE returns the option value, the type of which is uint.
 //
 // Return an error if no the option or the type of the option isn't uint.
-func (g *OptGroup) UintE(name string) (uint, error) {
+func (g valueGetter) UintE(name string) (uint, error) {
 	v, err := g.getValue(name, uintType)
 	if err != nil {
 		return 0, err
@@ -724,7 +1003,7 @@ func (g *OptGroup) UintE(name string) (uint, error) {
 }
 
 // UintD is the same as UintE, but returns the default if there is an error.
-func (g *OptGroup) UintD(name string, _default uint) uint {
+func (g valueGetter) UintD(name string, _default uint) uint {
 	if value, err := g.UintE(name); err == nil {
 		return value
 	}
@@ -732,7 +1011,7 @@ func (g *OptGroup) UintD(name string, _default uint) uint {
 }
 
 // Uint is the same as UintE, but panic if there is an error.
-func (g *OptGroup) Uint(name string) uint {
+func (g valueGetter) Uint(name string) uint {
 	value, err := g.UintE(name)
 	if err != nil {
 		panic(err)
@@ -743,7 +1022,7 @@ func (g *OptGroup) Uint(name string) uint {
 // Uint8E returns the option value, the type of which is uint8.
 //
 // Return an error if no the option or the type of the option isn't uint8.
-func (g *OptGroup) Uint8E(name string) (uint8, error) {
+func (g valueGetter) Uint8E(name string) (uint8, error) {
 	v, err := g.getValue(name, uint8Type)
 	if err != nil {
 		return 0, err
@@ -752,7 +1031,7 @@ func (g *OptGroup) Uint8E(name string) (uint8, error) {
 }
 
 // Uint8D is the same as Uint8E, but returns the default if there is an error.
-func (g *OptGroup) Uint8D(name string, _default uint8) uint8 {
+func (g valueGetter) Uint8D(name string, _default uint8) uint8 {
 	if value, err := g.Uint8E(name); err == nil {
 		return value
 	}
@@ -760,7 +1039,7 @@ func (g *OptGroup) Uint8D(name string, _default uint8) uint8 {
 }
 
 // Uint8 is the same as Uint8E, but panic if there is an error.
-func (g *OptGroup) Uint8(name string) uint8 {
+func (g valueGetter) Uint8(name string) uint8 {
 	value, err := g.Uint8E(name)
 	if err != nil {
 		panic(err)
@@ -771,7 +1050,7 @@ func (g *OptGroup) Uint8(name string) uint8 {
 // Uint16E returns the option value, the type of which is uint16.
 //
 // Return an error if no the option or the type of the option isn't uint16.
-func (g *OptGroup) Uint16E(name string) (uint16, error) {
+func (g valueGetter) Uint16E(name string) (uint16, error) {
 	v, err := g.getValue(name, uint16Type)
 	if err != nil {
 		return 0, err
@@ -780,7 +1059,7 @@ func (g *OptGroup) Uint16E(name string) (uint16, error) {
 }
 
 // Uint16D is the same as Uint16E, but returns the default if there is an error.
-func (g *OptGroup) Uint16D(name string, _default uint16) uint16 {
+func (g valueGetter) Uint16D(name string, _default uint16) uint16 {
 	if value, err := g.Uint16E(name); err == nil {
 		return value
 	}
@@ -788,7 +1067,7 @@ func (g *OptGroup) Uint16D(name string, _default uint16) uint16 {
 }
 
 // Uint16 is the same as Uint16E, but panic if there is an error.
-func (g *OptGroup) Uint16(name string) uint16 {
+func (g valueGetter) Uint16(name string) uint16 {
 	value, err := g.Uint16E(name)
 	if err != nil {
 		panic(err)
@@ -799,7 +1078,7 @@ func (g *OptGroup) Uint16(name string) uint16 {
 // Uint32E returns the option value, the type of which is uint32.
 //
 // Return an error if no the option or the type of the option isn't uint32.
-func (g *OptGroup) Uint32E(name string) (uint32, error) {
+func (g valueGetter) Uint32E(name string) (uint32, error) {
 	v, err := g.getValue(name, uint32Type)
 	if err != nil {
 		return 0, err
@@ -808,7 +1087,7 @@ func (g *OptGroup) Uint32E(name string) (uint32, error) {
 }
 
 // Uint32D is the same as Uint32E, but returns the default if there is an error.
-func (g *OptGroup) Uint32D(name string, _default uint32) uint32 {
+func (g valueGetter) Uint32D(name string, _default uint32) uint32 {
 	if value, err := g.Uint32E(name); err == nil {
 		return value
 	}
@@ -816,7 +1095,7 @@ func (g *OptGroup) Uint32D(name string, _default uint32) uint32 {
 }
 
 // Uint32 is the same as Uint32E, but panic if there is an error.
-func (g *OptGroup) Uint32(name string) uint32 {
+func (g valueGetter) Uint32(name string) uint32 {
 	value, err := g.Uint32E(name)
 	if err != nil {
 		panic(err)
@@ -827,7 +1106,7 @@ func (g *OptGroup) Uint32(name string) uint32 {
 // Uint64E returns the option value, the type of which is uint64.
 //
 // Return an error if no the option or the type of the option isn't uint64.
-func (g *OptGroup) Uint64E(name string) (uint64, error) {
+func (g valueGetter) Uint64E(name string) (uint64, error) {
 	v, err := g.getValue(name, uint64Type)
 	if err != nil {
 		return 0, err
@@ -836,7 +1115,7 @@ func (g *OptGroup) Uint64E(name string) (uint64, error) {
 }
 
 // Uint64D is the same as Uint64E, but returns the default if there is an error.
-func (g *OptGroup) Uint64D(name string, _default uint64) uint64 {
+func (g valueGetter) Uint64D(name string, _default uint64) uint64 {
 	if value, err := g.Uint64E(name); err == nil {
 		return value
 	}
@@ -844,7 +1123,7 @@ func (g *OptGroup) Uint64D(name string, _default uint64) uint64 {
 }
 
 // Uint64 is the same as Uint64E, but panic if there is an error.
-func (g *OptGroup) Uint64(name string) uint64 {
+func (g valueGetter) Uint64(name string) uint64 {
 	value, err := g.Uint64E(name)
 	if err != nil {
 		panic(err)
@@ -855,7 +1134,7 @@ func (g *OptGroup) Uint64(name string) uint64 {
 // Float32E returns the option value, the type of which is float32.
 //
 // Return an error if no the option or the type of the option isn't float32.
-func (g *OptGroup) Float32E(name string) (float32, error) {
+func (g valueGetter) Float32E(name string) (float32, error) {
 	v, err := g.getValue(name, float32Type)
 	if err != nil {
 		return 0, err
@@ -865,7 +1144,7 @@ func (g *OptGroup) Float32E(name string) (float32, error) {
 
 // Float32D is the same as Float32E, but returns the default value if there is
 // an error.
-func (g *OptGroup) Float32D(name string, _default float32) float32 {
+func (g valueGetter) Float32D(name string, _default float32) float32 {
 	if value, err := g.Float32E(name); err == nil {
 		return value
 	}
@@ -873,7 +1152,7 @@ func (g *OptGroup) Float32D(name string, _default float32) float32 {
 }
 
 // Float32 is the same as Float32E, but panic if there is an error.
-func (g *OptGroup) Float32(name string) float32 {
+func (g valueGetter) Float32(name string) float32 {
 	value, err := g.Float32E(name)
 	if err != nil {
 		panic(err)
@@ -884,7 +1163,7 @@ func (g *OptGroup) Float32(name string) float32 {
 // Float64E returns the option value, the type of which is float64.
 //
 // Return an error if no the option or the type of the option isn't float64.
-func (g *OptGroup) Float64E(name string) (float64, error) {
+func (g valueGetter) Float64E(name string) (float64, error) {
 	v, err := g.getValue(name, float64Type)
 	if err != nil {
 		return 0, err
@@ -894,7 +1173,7 @@ func (g *OptGroup) Float64E(name string) (float64, error) {
 
 // Float64D is the same as Float64E, but returns the default value if there is
 // an error.
-func (g *OptGroup) Float64D(name string, _default float64) float64 {
+func (g valueGetter) Float64D(name string, _default float64) float64 {
 	if value, err := g.Float64E(name); err == nil {
 		return value
 	}
@@ -902,7 +1181,7 @@ func (g *OptGroup) Float64D(name string, _default float64) float64 {
 }
 
 // Float64 is the same as Float64E, but panic if there is an error.
-func (g *OptGroup) Float64(name string) float64 {
+func (g valueGetter) Float64(name string) float64 {
 	value, err := g.Float64E(name)
 	if err != nil {
 		panic(err)
@@ -913,7 +1192,7 @@ func (g *OptGroup) Float64(name string) float64 {
 // DurationE returns the option value, the type of which is time.Duration.
 //
 // Return an error if no the option or the type of the option isn't time.Duration.
-func (g *OptGroup) DurationE(name string) (time.Duration, error) {
+func (g valueGetter) DurationE(name string) (time.Duration, error) {
 	v, err := g.getValue(name, durationType)
 	if err != nil {
 		return 0, err
@@ -923,7 +1202,7 @@ func (g *OptGroup) DurationE(name string) (time.Duration, error) {
 
 // DurationD is the same as DurationE, but returns the default value if there is
 // an error.
-func (g *OptGroup) DurationD(name string, _default time.Duration) time.Duration {
+func (g valueGetter) DurationD(name string, _default time.Duration) time.Duration {
 	if value, err := g.DurationE(name); err == nil {
 		return value
 	}
@@ -931,7 +1210,7 @@ func (g *OptGroup) DurationD(name string, _default time.Duration) time.Duration
 }
 
 // Duration is the same as DurationE, but panic if there is an error.
-func (g *OptGroup) Duration(name string) time.Duration {
+func (g valueGetter) Duration(name string) time.Duration {
 	value, err := g.DurationE(name)
 	if err != nil {
 		panic(err)
@@ -942,7 +1221,7 @@ func (g *OptGroup) Duration(name string) time.Duration {
 // TimeE returns the option value, the type of which is time.Time.
 //
 // Return an error if no the option or the type of the option isn't time.Time.
-func (g *OptGroup) TimeE(name string) (time.Time, error) {
+func (g valueGetter) TimeE(name string) (time.Time, error) {
 	v, err := g.getValue(name, timeType)
 	if err != nil {
 		return time.Time{}, err
@@ -952,7 +1231,7 @@ func (g *OptGroup) TimeE(name string) (time.Time, error) {
 
 // TimeD is the same as TimeE, but returns the default value if there is
 // an error.
-func (g *OptGroup) TimeD(name string, _default time.Time) time.Time {
+func (g valueGetter) TimeD(name string, _default time.Time) time.Time {
 	if value, err := g.TimeE(name); err == nil {
 		return value
 	}
@@ -960,7 +1239,7 @@ func (g *OptGroup) TimeD(name string, _default time.Time) time.Time {
 }
 
 // Time is the same as TimeE, but panic if there is an error.
-func (g *OptGroup) Time(name string) time.Time {
+func (g valueGetter) Time(name string) time.Time {
 	value, err := g.TimeE(name)
 	if err != nil {
 		panic(err)
@@ -971,7 +1250,7 @@ func (g *OptGroup) Time(name string) time.Time {
 // StringsE returns the option value, the type of which is []string.
 //
 // Return an error if no the option or the type of the option isn't []string.
-func (g *OptGroup) StringsE(name string) ([]string, error) {
+func (g valueGetter) StringsE(name string) ([]string, error) {
 	v, err := g.getValue(name, stringsType)
 	if err != nil {
 		return nil, err
@@ -981,7 +1260,7 @@ func (g *OptGroup) StringsE(name string) ([]string, error) {
 
 // StringsD is the same as StringsE, but returns the default value if there is
 // an error.
-func (g *OptGroup) StringsD(name string, _default []string) []string {
+func (g valueGetter) StringsD(name string, _default []string) []string {
 	if value, err := g.StringsE(name); err == nil {
 		return value
 	}
@@ -989,7 +1268,7 @@ func (g *OptGroup) StringsD(name string, _default []string) []string {
 }
 
 // Strings is the same as StringsE, but panic if there is an error.
-func (g *OptGroup) Strings(name string) []string {
+func (g valueGetter) Strings(name string) []string {
 	value, err := g.StringsE(name)
 	if err != nil {
 		panic(err)
@@ -1000,7 +1279,7 @@ func (g *OptGroup) Strings(name string) []string {
 // IntsE returns the option value, the type of which is []int.
 //
 // Return an error if no the option or the type of the option isn't []int.
-func (g *OptGroup) IntsE(name string) ([]int, error) {
+func (g valueGetter) IntsE(name string) ([]int, error) {
 	v, err := g.getValue(name, intsType)
 	if err != nil {
 		return nil, err
@@ -1010,7 +1289,7 @@ func (g *OptGroup) IntsE(name string) ([]int, error) {
 
 // IntsD is the same as IntsE, but returns the default value if there is
 // an error.
-func (g *OptGroup) IntsD(name string, _default []int) []int {
+func (g valueGetter) IntsD(name string, _default []int) []int {
 	if value, err := g.IntsE(name); err == nil {
 		return value
 	}
@@ -1018,7 +1297,7 @@ func (g *OptGroup) IntsD(name string, _default []int) []int {
 }
 
 // Ints is the same as IntsE, but panic if there is an error.
-func (g *OptGroup) Ints(name string) []int {
+func (g valueGetter) Ints(name string) []int {
 	value, err := g.IntsE(name)
 	if err != nil {
 		panic(err)
@@ -1029,7 +1308,7 @@ func (g *OptGroup) Ints(name string) []int {
 // Int64sE returns the option value, the type of which is []int64.
 //
 // Return an error if no the option or the type of the option isn't []int64.
-func (g *OptGroup) Int64sE(name string) ([]int64, error) {
+func (g valueGetter) Int64sE(name string) ([]int64, error) {
 	v, err := g.getValue(name, int64sType)
 	if err != nil {
 		return nil, err
@@ -1039,7 +1318,7 @@ func (g *OptGroup) Int64sE(name string) ([]int64, error) {
 
 // Int64sD is the same as Int64sE, but returns the default value if there is
 // an error.
-func (g *OptGroup) Int64sD(name string, _default []int64) []int64 {
+func (g valueGetter) Int64sD(name string, _default []int64) []int64 {
 	if value, err := g.Int64sE(name); err == nil {
 		return value
 	}
@@ -1047,7 +1326,7 @@ func (g *OptGroup) Int64sD(name string, _default []int64) []int64 {
 }
 
 // Int64s is the same as Int64s, but panic if there is an error.
-func (g *OptGroup) Int64s(name string) []int64 {
+func (g valueGetter) Int64s(name string) []int64 {
 	value, err := g.Int64sE(name)
 	if err != nil {
 		panic(err)
@@ -1058,7 +1337,7 @@ func (g *OptGroup) Int64s(name string) []int64 {
 // UintsE returns the option value, the type of which is []uint.
 //
 // Return an error if no the option or the type of the option isn't []uint.
-func (g *OptGroup) UintsE(name string) ([]uint, error) {
+func (g valueGetter) UintsE(name string) ([]uint, error) {
 	v, err := g.getValue(name, uintsType)
 	if err != nil {
 		return nil, err
@@ -1068,7 +1347,7 @@ func (g *OptGroup) UintsE(name string) ([]uint, error) {
 
 // UintsD is the same as UintsE, but returns the default value if there is
 // an error.
-func (g *OptGroup) UintsD(name string, _default []uint) []uint {
+func (g valueGetter) UintsD(name string, _default []uint) []uint {
 	if value, err := g.UintsE(name); err == nil {
 		return value
 	}
@@ -1076,7 +1355,7 @@ func (g *OptGroup) UintsD(name string, _default []uint) []uint {
 }
 
 // Uints is the same as UintsE, but panic if there is an error.
-func (g *OptGroup) Uints(name string) []uint {
+func (g valueGetter) Uints(name string) []uint {
 	value, err := g.UintsE(name)
 	if err != nil {
 		panic(err)
@@ -1087,7 +1366,7 @@ func (g *OptGroup) Uints(name string) []uint {
 // Uint64sE returns the option value, the type of which is []uint64.
 //
 // Return an error if no the option or the type of the option isn't []uint64.
-func (g *OptGroup) Uint64sE(name string) ([]uint64, error) {
+func (g valueGetter) Uint64sE(name string) ([]uint64, error) {
 	v, err := g.getValue(name, uint64sType)
 	if err != nil {
 		return nil, err
@@ -1097,7 +1376,7 @@ func (g *OptGroup) Uint64sE(name string) ([]uint64, error) {
 
 // Uint64sD is the same as Uint64sE, but returns the default value if there is
 // an error.
-func (g *OptGroup) Uint64sD(name string, _default []uint64) []uint64 {
+func (g valueGetter) Uint64sD(name string, _default []uint64) []uint64 {
 	if value, err := g.Uint64sE(name); err == nil {
 		return value
 	}
@@ -1105,7 +1384,7 @@ func (g *OptGroup) Uint64sD(name string, _default []uint64) []uint64 {
 }
 
 // Uint64s is the same as Uint64sE, but panic if there is an error.
-func (g *OptGroup) Uint64s(name string) []uint64 {
+func (g valueGetter) Uint64s(name string) []uint64 {
 	value, err := g.Uint64sE(name)
 	if err != nil {
 		panic(err)
@@ -1116,7 +1395,7 @@ func (g *OptGroup) Uint64s(name string) []uint64 {
 // Float64sE returns the option value, the type of which is []float64.
 //
 // Return an error if no the option or the type of the option isn't []float64.
-func (g *OptGroup) Float64sE(name string) ([]float64, error) {
+func (g valueGetter) Float64sE(name string) ([]float64, error) {
 	v, err := g.getValue(name, float64sType)
 	if err != nil {
 		return nil, err
@@ -1126,7 +1405,7 @@ func (g *OptGroup) Float64sE(name string) ([]float64, error) {
 
 // Float64sD is the same as Float64sE, but returns the default value if there is
 // an error.
-func (g *OptGroup) Float64sD(name string, _default []float64) []float64 {
+func (g valueGetter) Float64sD(name string, _default []float64) []float64 {
 	if value, err := g.Float64sE(name); err == nil {
 		return value
 	}
@@ -1134,7 +1413,7 @@ func (g *OptGroup) Float64sD(name string, _default []float64) []float64 {
 }
 
 // Float64s is the same as Float64sE, but panic if there is an error.
-func (g *OptGroup) Float64s(name string) []float64 {
+func (g valueGetter) Float64s(name string) []float64 {
 	value, err := g.Float64sE(name)
 	if err != nil {
 		panic(err)
@@ -1145,7 +1424,7 @@ func (g *OptGroup) Float64s(name string) []float64 {
 // DurationsE returns the option value, the type of which is []time.Duration.
 //
 // Return an error if no the option or the type of the option isn't []time.Duration.
-func (g *OptGroup) DurationsE(name string) ([]time.Duration, error) {
+func (g valueGetter) DurationsE(name string) ([]time.Duration, error) {
 	v, err := g.getValue(name, durationsType)
 	if err != nil {
 		return nil, err
@@ -1155,7 +1434,7 @@ func (g *OptGroup) DurationsE(name string) ([]time.Duration, error) {
 
 // DurationsD is the same as DurationsE, but returns the default value if there is
 // an error.
-func (g *OptGroup) DurationsD(name string, _default []time.Duration) []time.Duration {
+func (g valueGetter) DurationsD(name string, _default []time.Duration) []time.Duration {
 	if value, err := g.DurationsE(name); err == nil {
 		return value
 	}
@@ -1163,7 +1442,7 @@ func (g *OptGroup) DurationsD(name string, _default []time.Duration) []time.Dura
 }
 
 // Durations is the same as DurationsE, but panic if there is an error.
-func (g *OptGroup) Durations(name string) []time.Duration {
+func (g valueGetter) Durations(name string) []time.Duration {
 	value, err := g.DurationsE(name)
 	if err != nil {
 		panic(err)
@@ -1174,7 +1453,7 @@ func (g *OptGroup) Durations(name string) []time.Duration {
 // TimesE returns the option value, the type of which is []time.Time.
 //
 // Return an error if no the option or the type of the option isn't []time.Time.
-func (g *OptGroup) TimesE(name string) ([]time.Time, error) {
+func (g valueGetter) TimesE(name string) ([]time.Time, error) {
 	v, err := g.getValue(name, timesType)
 	if err != nil {
 		return nil, err
@@ -1184,7 +1463,7 @@ func (g *OptGroup) TimesE(name string) ([]time.Time, error) {
 
 // TimesD is the same as TimesE, but returns the default value if there is
 // an error.
-func (g *OptGroup) TimesD(name string, _default []time.Time) []time.Time {
+func (g valueGetter) TimesD(name string, _default []time.Time) []time.Time {
 	if value, err := g.TimesE(name); err == nil {
 		return value
 	}
@@ -1192,10 +1471,38 @@ func (g *OptGroup) TimesD(name string, _default []time.Time) []time.Time {
 }
 
 // Times is the same as TimesE, but panic if there is an error.
-func (g *OptGroup) Times(name string) []time.Time {
+func (g valueGetter) Times(name string) []time.Time {
 	value, err := g.TimesE(name)
 	if err != nil {
 		panic(err)
 	}
 	return value
 }
+
+// StringMapE returns the option value, the type of which is map[string]string.
+//
+// Return an error if no the option or the type of the option isn't map[string]string.
+func (g valueGetter) StringMapE(name string) (map[string]string, error) {
+	v, err := g.getValue(name, stringMapType)
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]string), nil
+}
+
+// StringMapD is the same as StringMapE, but returns the default if there is an error.
+func (g valueGetter) StringMapD(name string, _default map[string]string) map[string]string {
+	if value, err := g.StringMapE(name); err == nil {
+		return value
+	}
+	return _default
+}
+
+// StringMap is the same as StringMapE, but panic if there is an error.
+func (g valueGetter) StringMap(name string) map[string]string {
+	value, err := g.StringMapE(name)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
@@ -0,0 +1,371 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The predefined names of the layers, the priorities of which are 0, 10,
+// 100 and 1000, that's, the priorities of the CLI, the environment variable,
+// the config file parsers and the default value.
+const (
+	LayerCli     = "cli"
+	LayerEnv     = "env"
+	LayerFile    = "file"
+	LayerDefault = "default"
+)
+
+// Origin is the location of the value in the source, which is set by
+// the parser by SetOptValueWithOrigin.
+type Origin struct {
+	File   string // The path of the config file.
+	Line   int    // The line number in the config file, which starts with 1.
+	EnvVar string // The name of the environment variable.
+}
+
+// String returns the description of the origin, such as "file app.ini:3"
+// or "env APP_PORT". Return "" if the origin is empty.
+func (o Origin) String() string {
+	var origins []string
+	if o.EnvVar != "" {
+		origins = append(origins, "env "+o.EnvVar)
+	}
+	if o.File != "" {
+		if o.Line > 0 {
+			origins = append(origins, fmt.Sprintf("file %s:%d", o.File, o.Line))
+		} else {
+			origins = append(origins, "file "+o.File)
+		}
+	}
+	return strings.Join(origins, ", ")
+}
+
+// Layer is the value of the option at a priority with its provenance.
+//
+// Each option keeps a layer stack, that's, one value per source, file and
+// priority, the value of the highest priority layer of which is the current
+// value of the option. If more than one source or file offers the value at
+// the same priority, the later one wins, but the value set at runtime always
+// wins over those from the parsers. So removing the value of the current layer reveals
+// the value of the next.
+type Layer struct {
+	Priority int
+
+	// Source is the name of the parser which sets the value, or the name
+	// of the layer if no parser is parsing.
+	Source string
+
+	Value  interface{}
+	Origin Origin    // The location of the value in the source.
+	Time   time.Time // The time when the value is set.
+
+	// Secret reports whether the value is a secret, such as the value read
+	// from the file of the environment variable "*_FILE".
+	Secret bool
+
+	// Runtime reports whether the value is set at runtime when no parser is
+	// parsing, such as by SetOptValue, SetLayerValue or Txn, which is kept
+	// by Reload even if Source is the same as the name of a parser.
+	Runtime bool
+}
+
+func (c *Config) newLayer(priority int, value interface{}, origin Origin) Layer {
+	source, runtime := c.getLayerSource(priority)
+	return Layer{
+		Origin:   origin,
+		Priority: priority,
+		Source:   source,
+		Value:    value,
+		Runtime:  runtime,
+	}
+}
+
+// AddLayer adds the named layer with the priority, or resets its priority,
+// which may be used by SetLayerValue and UnsetLayerValue instead of
+// the bare priority.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) AddLayer(name string, priority int) *Config {
+	c.panicIsParsed(true)
+	if priority < 0 {
+		panic(fmt.Errorf("the priority must not be the negative"))
+	}
+	c.layers[name] = priority
+	return c
+}
+
+// GetLayerPriority returns the priority of the named layer.
+func (c *Config) GetLayerPriority(name string) (priority int, ok bool) {
+	priority, ok = c.layers[name]
+	return
+}
+
+// getLayerSource returns the source of the value at the priority, which is
+// the name of the parser parsing, or the name of the layer with the priority
+// and true as the value is set at runtime.
+func (c *Config) getLayerSource(priority int) (source string, runtime bool) {
+	c.slock.Lock()
+	source = c.current
+	c.slock.Unlock()
+	if source != "" {
+		return source, false
+	}
+
+	for name, prio := range c.layers {
+		if prio == priority && (source == "" || name < source) {
+			source = name
+		}
+	}
+	return source, true
+}
+
+// SetLayerValue is the same as SetOptValue, but uses the priority
+// of the named layer. See AddLayer.
+func (c *Config) SetLayerValue(layer, groupName, optName string, optValue interface{}) error {
+	priority, ok := c.layers[layer]
+	if !ok {
+		return fmt.Errorf("no layer '%s'", layer)
+	}
+
+	_, runtime := c.getLayerSource(priority)
+	layerValue := Layer{Priority: priority, Source: layer, Value: optValue, Runtime: runtime}
+	return c.setOptValue(layerValue, groupName, optName, false)
+}
+
+// UnsetLayerValue is the same as UnsetOptValue, but uses the priority
+// of the named layer. See AddLayer.
+func (c *Config) UnsetLayerValue(layer, groupName, optName string) error {
+	priority, ok := c.layers[layer]
+	if !ok {
+		return fmt.Errorf("no layer '%s'", layer)
+	}
+	return c.UnsetOptValue(priority, groupName, optName)
+}
+
+// UnsetOptValue removes the values of the option in the group from all
+// the sources at the priority, such as the key is deleted from the source.
+// It's thread-safe.
+//
+// If the value is the current value, the value of the next layer becomes
+// the current value, or the option has no value if no layer is left.
+// And the observers of Observe and ObserveChanges are called with the new
+// value, which is nil if no value. After parsing, the value is not unset and
+// an error is returned if the state validators fail against the new state.
+func (c *Config) UnsetOptValue(priority int, groupName, optName string) error {
+	if group := c.getGroupByName(groupName, false); group != nil {
+		return group.unsetOptValue(priority, optName)
+	}
+	return fmt.Errorf("no group '%s'", groupName)
+}
+
+func (g *OptGroup) unsetOptValue(priority int, name string) (err error) {
+	c := g.conf
+	c.txnLock.Lock()
+
+	// Validate the state with the value of the next layer if the current
+	// value is unset.
+	g.lock.RLock()
+	opt, ok := g.opts[name]
+	change := Change{Group: g.name, Name: name, Old: g.values[name]}
+	changed := ok && len(opt.layers) > 0 && opt.layers[0].Priority == priority
+	if changed {
+		for _, layer := range opt.layers {
+			if layer.Priority != priority {
+				change.New = layer.Value
+				break
+			}
+		}
+	}
+	g.lock.RUnlock()
+
+	if !ok {
+		c.txnLock.Unlock()
+		return fmt.Errorf("no the option '%s' in the group '%s'", name, g.name)
+	} else if changed {
+		if err = c.validateChange(change); err != nil {
+			c.txnLock.Unlock()
+			return
+		}
+	}
+
+	g.lock.Lock()
+	if opt.unsetLayer(priority) && changed {
+		if len(opt.layers) > 0 {
+			opt.prio = opt.layers[0].Priority
+			g.values[name] = change.New
+		} else {
+			opt.prio = 1 << 31
+			delete(g.values, name)
+		}
+
+		if opt.field.IsValid() {
+			if change.New == nil {
+				opt.field.Set(reflect.Zero(opt.field.Type()))
+			} else {
+				opt.field.Set(reflect.ValueOf(change.New))
+			}
+		}
+	}
+	g.lock.Unlock()
+	if changed {
+		c.updateSnapshot()
+	}
+	c.txnLock.Unlock()
+
+	if changed {
+		c.debug("Unset [%s]:[%s] in the layer %d", g.name, name, priority)
+		if c.watch != nil {
+			c.watch(g.name, name, change.New)
+		}
+		c.emitChanges([]Change{change})
+	}
+	return
+}
+
+// Layers returns the layer stack of the option named name in the ascending
+// order of the priority, the first of which is the current value.
+//
+// Return nil if the option does not exist or has no value.
+//
+// Notice: it's used to inspect the values from all the sources for debugging,
+// so neither the value of the sensitive option nor that of the secret layer
+// is masked. Check IsSensitiveOpt and Layer.Secret if outputting them.
+func (g *OptGroup) Layers(name string) []Layer {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	opt, ok := g.opts[name]
+	if !ok || len(opt.layers) == 0 {
+		return nil
+	}
+	return append([]Layer(nil), opt.layers...)
+}
+
+// Layers is the same as Group("").Layers(name).
+func (c *Config) Layers(name string) []Layer {
+	return c.Group("").Layers(name)
+}
+
+// Source returns the layer of the current value of the option named name,
+// which records where the value comes from, such as the parser, the priority,
+// the file and line, the environment variable, and the time.
+//
+// Return false if the option does not exist or has no value.
+func (g *OptGroup) Source(name string) (layer Layer, ok bool) {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	if opt := g.opts[name]; opt != nil && len(opt.layers) > 0 && opt.prio == opt.layers[0].Priority {
+		return opt.layers[0], true
+	}
+	return
+}
+
+// Explain returns the description of all the sources which have offered
+// a value of the option named name, and why the value won or lost,
+// for example,
+//
+//    [DEFAULT]:[port] = 8080
+//      * getopt (priority 0): 8080, won as the highest priority
+//      - env (priority 10, env APP_PORT): 9090, lost to getopt (priority 0)
+//      - default (priority 1000): 80, lost to getopt (priority 0)
+//
+// If more than one source offers the value at the same priority, the earlier
+// ones are reported as overridden by a later source at the same priority.
+//
+// Notice: the value of the sensitive option or the secret layer is masked.
+func (g *OptGroup) Explain(name string) string {
+	g.lock.RLock()
+	opt := g.opts[name]
+	var layers []Layer
+	if opt != nil {
+		layers = append(layers, opt.layers...)
+	}
+	g.lock.RUnlock()
+
+	if opt == nil {
+		return fmt.Sprintf("[%s]:[%s] does not exist\n", g.name, name)
+	} else if len(layers) == 0 {
+		return fmt.Sprintf("[%s]:[%s] has no value\n", g.name, name)
+	}
+
+	sensitive := IsSensitiveOpt(opt.opt)
+	formatValue := func(layer Layer) string {
+		if sensitive || layer.Secret {
+			return "******"
+		}
+		return fmt.Sprintf("%v", layer.Value)
+	}
+
+	formatSource := func(layer Layer) string {
+		source := layer.Source
+		if source == "" {
+			source = "unknown"
+		}
+		if origin := layer.Origin.String(); origin != "" {
+			return fmt.Sprintf("%s (priority %d, %s)", source, layer.Priority, origin)
+		}
+		return fmt.Sprintf("%s (priority %d)", source, layer.Priority)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	winner := layers[0]
+	fmt.Fprintf(buf, "[%s]:[%s] = %s\n", g.name, name, formatValue(winner))
+	for i, layer := range layers {
+		at := ""
+		if !layer.Time.IsZero() {
+			at = " at " + layer.Time.Format(time.RFC3339)
+		}
+
+		switch {
+		case i == 0:
+			fmt.Fprintf(buf, "  * %s: %s, won as the highest priority%s\n",
+				formatSource(layer), formatValue(layer), at)
+		case layer.Priority == winner.Priority:
+			fmt.Fprintf(buf, "  - %s: %s, overridden by a later source at the same priority%s\n",
+				formatSource(layer), formatValue(layer), at)
+		default:
+			fmt.Fprintf(buf, "  - %s: %s, lost to %s%s\n", formatSource(layer),
+				formatValue(layer), formatSource(Layer{Priority: winner.Priority,
+					Source: winner.Source}), at)
+		}
+	}
+	return buf.String()
+}
+
+// Explain returns the explanations of all the options in all the groups.
+// See OptGroup.Explain.
+func (c *Config) Explain() string {
+	groups := c.Groups()
+	sort.Slice(groups, func(i, j int) bool { return groups[i].FullName() < groups[j].FullName() })
+
+	var explains []string
+	for _, group := range groups {
+		opts := group.AllOpts()
+		sort.Slice(opts, func(i, j int) bool { return opts[i].Name() < opts[j].Name() })
+		for _, opt := range opts {
+			explains = append(explains, group.Explain(opt.Name()))
+		}
+	}
+	return strings.Join(explains, "")
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLayer(t *testing.T) {
+	os.Setenv("LAYER_PORT", "9090")
+	defer os.Unsetenv("LAYER_PORT")
+
+	var changes []interface{}
+	conf := NewConfig().AddParser(NewGetoptCliParser(true), NewEnvVarParser("layer"))
+	conf.AddLayer("admin", 5)
+	conf.RegisterCliOpt("", Int("port", 80, ""))
+	conf.Observe(func(group, name string, value interface{}) {
+		changes = append(changes, value)
+	})
+	if err := conf.Parse("--port", "8080"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Layer{
+		{Priority: 0, Source: "getopt", Value: 8080},
+		{Priority: 10, Source: "env", Value: 9090, Origin: Origin{EnvVar: "LAYER_PORT"}},
+		{Priority: 1000, Source: LayerDefault, Value: 80},
+	}
+	layers := conf.Layers("port")
+	for i := range layers {
+		if layers[i].Time.IsZero() {
+			t.Errorf("the time of the layer %d is not set", i)
+		}
+		layers[i].Time = time.Time{}
+	}
+	if !reflect.DeepEqual(layers, expected) {
+		t.Errorf("unexpected layers %+v", layers)
+	}
+	if v := conf.Int("port"); v != 8080 {
+		t.Errorf("expect %d, but got %d", 8080, v)
+	}
+
+	changes = nil
+	if err := conf.SetLayerValue("admin", "", "port", 7070); err != nil {
+		t.Fatal(err)
+	} else if v := conf.Int("port"); v != 8080 {
+		t.Errorf("expect %d, but got %d", 8080, v)
+	}
+
+	if err := conf.UnsetLayerValue(LayerCli, "", "port"); err != nil {
+		t.Fatal(err)
+	} else if v := conf.Int("port"); v != 7070 {
+		t.Errorf("expect %d, but got %d", 7070, v)
+	}
+
+	// Unset the value which is not the current.
+	if err := conf.UnsetOptValue(10, "", "port"); err != nil {
+		t.Fatal(err)
+	} else if v := conf.Int("port"); v != 7070 {
+		t.Errorf("expect %d, but got %d", 7070, v)
+	}
+
+	conf.UnsetLayerValue("admin", "", "port")
+	conf.UnsetLayerValue(LayerDefault, "", "port")
+	if v := conf.Value("port"); v != nil {
+		t.Errorf("expect no value, but got %v", v)
+	}
+	if layers := conf.Layers("port"); layers != nil {
+		t.Errorf("unexpected layers %+v", layers)
+	}
+
+	if !reflect.DeepEqual(changes, []interface{}{7070, 80, nil}) {
+		t.Errorf("unexpected changes %v", changes)
+	}
+
+	if err := conf.SetLayerValue("nonexistent", "", "port", 1); err == nil {
+		t.Error("expect an error for the nonexistent layer")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	file, err := ioutil.TempFile("", "go-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("[DEFAULT]\n\nport = 9090\npassword = secret\n")
+	file.Close()
+
+	conf := NewConfig().AddParser(NewGetoptCliParser(true), NewSimpleIniParser("config-file"))
+	conf.RegisterOpts("", []Opt{
+		Int("port", 80, ""),
+		Str("password", "", "").(SensitiveSetter).SetSensitive(true),
+		Str("name", "", ""),
+	})
+	if err = conf.Parse("--config-file", file.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	layer, ok := conf.Group("").Source("port")
+	if !ok {
+		t.Fatal("no source of the option 'port'")
+	} else if layer.Source != "ini" || layer.Priority != 100 || layer.Origin.File != file.Name() ||
+		layer.Origin.Line != 3 {
+		t.Errorf("unexpected source %+v", layer)
+	}
+
+	explain := conf.Group("").Explain("port")
+	for _, s := range []string{
+		"[DEFAULT]:[port] = 9090\n",
+		fmt.Sprintf("  * ini (priority 100, file %s:3): 9090, won as the highest priority at ", file.Name()),
+		"  - default (priority 1000): 80, lost to ini (priority 100) at ",
+	} {
+		if !strings.Contains(explain, s) {
+			t.Errorf("the explanation does not contain '%s':\n%s", s, explain)
+		}
+	}
+
+	// Another source at the same priority.
+	if err = conf.SetLayerValue(LayerFile, "", "port", 9191); err != nil {
+		t.Fatal(err)
+	} else if layers := conf.Layers("port"); len(layers) != 3 {
+		t.Errorf("expect %d layers, but got %d", 3, len(layers))
+	}
+	explain = conf.Group("").Explain("port")
+	for _, s := range []string{
+		"[DEFAULT]:[port] = 9191\n",
+		"  * file (priority 100): 9191, won as the highest priority at ",
+		fmt.Sprintf("  - ini (priority 100, file %s:3): 9090, overridden by a later source"+
+			" at the same priority at ", file.Name()),
+		"  - default (priority 1000): 80, lost to file (priority 100) at ",
+	} {
+		if !strings.Contains(explain, s) {
+			t.Errorf("the explanation does not contain '%s':\n%s", s, explain)
+		}
+	}
+
+	explain = conf.Explain()
+	if !strings.Contains(explain, "[DEFAULT]:[password] = ******\n") {
+		t.Errorf("the sensitive value is not masked:\n%s", explain)
+	}
+	if !strings.Contains(explain, "[DEFAULT]:[name] = \n") {
+		t.Errorf("no the explanation of the option 'name':\n%s", explain)
+	}
+}
+
+type lowPriorityParser struct{}
+
+func (p lowPriorityParser) Name() string         { return "low" }
+func (p lowPriorityParser) Priority() int        { return 2000 }
+func (p lowPriorityParser) Pre(c *Config) error  { return nil }
+func (p lowPriorityParser) Post(c *Config) error { return nil }
+func (p lowPriorityParser) Parse(c *Config) error {
+	return c.SetOptValue(2000, "", "port", 9999)
+}
+
+func TestLayerDefaultNotOverride(t *testing.T) {
+	conf := NewConfig().AddParser(lowPriorityParser{})
+	conf.RegisterOpt("", Int("port", 80, ""))
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.Int("port"); v != 9999 {
+		t.Errorf("expect %d, but got %d", 9999, v)
+	}
+	if layers := conf.Layers("port"); len(layers) != 1 || layers[0].Priority != 2000 {
+		t.Errorf("unexpected layers %+v", layers)
+	}
+}
+
+func TestLayerProfileFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "app.ini")
+	overlay := filepath.Join(dir, "app.prod.ini")
+	if err = ioutil.WriteFile(base, []byte("[DEFAULT]\nport = 9090\n"), 0644); err != nil {
+		t.Fatal(err)
+	} else if err = ioutil.WriteFile(overlay, []byte("[DEFAULT]\nport = 9191\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := NewConfig().AddParser(NewGetoptCliParser(true), NewSimpleIniParser("config-file"))
+	conf.SetProfiles("prod")
+	conf.RegisterOpt("", Int("port", 80, ""))
+	if err = conf.Parse("--config-file", base); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.Int("port"); v != 9191 {
+		t.Errorf("expect %d, but got %d", 9191, v)
+	}
+	if layers := conf.Layers("port"); len(layers) != 3 {
+		t.Errorf("expect %d layers, but got %+v", 3, layers)
+	}
+
+	explain := conf.Group("").Explain("port")
+	for _, s := range []string{
+		fmt.Sprintf("  * ini (priority 100, file %s:2): 9191, won as the highest priority at ", overlay),
+		fmt.Sprintf("  - ini (priority 100, file %s:2): 9090, overridden by a later source"+
+			" at the same priority at ", base),
+	} {
+		if !strings.Contains(explain, s) {
+			t.Errorf("the explanation does not contain '%s':\n%s", s, explain)
+		}
+	}
+}
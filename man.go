@@ -0,0 +1,387 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ManPage is the metadata of the man page generated by Config.GenManPage.
+type ManPage struct {
+	// Name is the name of the program, which is the base name of os.Args[0]
+	// by default.
+	Name string
+
+	// Section is the section of the man page, which is 1 for the command
+	// by default, or 5 for the format of the config file.
+	Section int
+
+	// Date is the date of the man page. If empty, it is the time from
+	// the environment variable SOURCE_DATE_EPOCH, or the current time.
+	Date string
+
+	// Manual is the title of the manual, which is "User Commands"
+	// for the section 1 or "File Formats" for the section 5 by default.
+	Manual string
+
+	// Short is the one-line description of the program in the section NAME.
+	Short string
+
+	// Description is the description of the program in the section DESCRIPTION.
+	Description string
+
+	// SeeAlso is the references in the section SEE ALSO, such as "app(5)".
+	SeeAlso []string
+}
+
+// GenManPage generates the roff man page, and writes it into w.
+//
+// For the section 1, it contains the global options grouped by the group
+// and the options of each command, with the help, the default and the names
+// of the environment variables, the environment variables, and the config
+// files read by the file parsers (see FileParser). The version comes from
+// SetVersion.
+//
+// For the section 5, it contains all the options, including the non-CLI
+// options, grouped by the section of the config file.
+func (c *Config) GenManPage(w io.Writer, page ManPage) error {
+	if page.Name == "" {
+		page.Name = filepath.Base(os.Args[0])
+	}
+	if page.Section == 0 {
+		page.Section = 1
+	}
+	if page.Date == "" {
+		page.Date = manDate()
+	}
+
+	buf := bytes.NewBuffer(nil)
+	switch page.Section {
+	case 1:
+		if page.Manual == "" {
+			page.Manual = "User Commands"
+		}
+		c.genCommandManPage(buf, page)
+	case 5:
+		if page.Manual == "" {
+			page.Manual = "File Formats"
+		}
+		c.genFileManPage(buf, page)
+	default:
+		return fmt.Errorf("unsupported man page section %d", page.Section)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func manDate() string {
+	t := time.Now()
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		t = time.Unix(epoch, 0).UTC()
+	}
+	return t.Format("January 2006")
+}
+
+func (c *Config) genManHeader(buf *bytes.Buffer, page ManPage, short string) {
+	source := page.Name
+	if _, version, _ := c.GetVersion(); version != "" {
+		source = fmt.Sprintf("%s %s", page.Name, version)
+	}
+
+	fmt.Fprintf(buf, ".TH \"%s\" \"%d\" \"%s\" \"%s\" \"%s\"\n", roffEscape(strings.ToUpper(page.Name)),
+		page.Section, roffEscape(page.Date), roffEscape(source), roffEscape(page.Manual))
+
+	buf.WriteString(".SH NAME\n")
+	if page.Short != "" {
+		short = page.Short
+	}
+	if short == "" {
+		fmt.Fprintf(buf, "%s\n", roffEscape(page.Name))
+	} else {
+		fmt.Fprintf(buf, "%s \\- %s\n", roffEscape(page.Name), roffEscape(short))
+	}
+}
+
+func (c *Config) genManFooter(buf *bytes.Buffer, page ManPage) {
+	c.genManFiles(buf)
+	if len(page.SeeAlso) > 0 {
+		buf.WriteString(".SH \"SEE ALSO\"\n")
+		fmt.Fprintf(buf, "%s\n", roffEscape(strings.Join(page.SeeAlso, ", ")))
+	}
+}
+
+func (c *Config) genCommandManPage(buf *bytes.Buffer, page ManPage) {
+	c.genManHeader(buf, page, "")
+
+	cmds := allCommands(c.commands)
+	buf.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(buf, ".B %s\n", roffEscape(page.Name))
+	if len(cmds) > 0 {
+		buf.WriteString("[\\fIOPTIONS\\fR] [\\fICOMMAND\\fR [\\fIOPTIONS\\fR]] [\\fIARGS\\fR]\n")
+	} else {
+		buf.WriteString("[\\fIOPTIONS\\fR] [\\fIARGS\\fR]\n")
+	}
+
+	if page.Description != "" {
+		buf.WriteString(".SH DESCRIPTION\n")
+		fmt.Fprintf(buf, "%s\n", roffEscape(page.Description))
+	}
+
+	var uos []UsageOpt
+	buf.WriteString(".SH OPTIONS\n")
+	for _, ug := range c.getCommandUsageGroups(nil, true) {
+		if ug.Name != "" {
+			fmt.Fprintf(buf, ".SS \"Options of %s\"\n", roffEscape(ug.Name))
+		}
+		for _, uo := range ug.Opts {
+			genManOpt(buf, roffEscape(strings.TrimSpace(uo.Flag)), uo)
+		}
+		uos = append(uos, ug.Opts...)
+	}
+	if name, _, help := c.GetVersion(); name != "" {
+		fmt.Fprintf(buf, ".TP\n\\fB%s\\fR\n%s\n", roffEscape(c.getCliStyle().prefix+name),
+			roffEscape(help))
+	}
+
+	if len(cmds) > 0 {
+		buf.WriteString(".SH COMMANDS\n")
+		for _, cmd := range cmds {
+			fmt.Fprintf(buf, ".SS \"%s\"\n", roffEscape(cmd.FullName()))
+			if help := cmd.Help(); help != "" {
+				fmt.Fprintf(buf, "%s\n", roffEscape(help))
+			}
+			for _, ug := range c.getCommandUsageGroups(cmd, true) {
+				for _, uo := range ug.Opts {
+					genManOpt(buf, roffEscape(strings.TrimSpace(uo.Flag)), uo)
+				}
+				uos = append(uos, ug.Opts...)
+			}
+		}
+	}
+
+	envs := make(map[string][]string, len(uos))
+	for _, uo := range uos {
+		for _, env := range uo.EnvVars {
+			envs[env] = append(envs[env], roffEscape(strings.TrimSpace(uo.Flag)))
+		}
+	}
+	if len(envs) > 0 {
+		names := make([]string, 0, len(envs))
+		for name := range envs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		buf.WriteString(".SH ENVIRONMENT\n")
+		for _, name := range names {
+			fmt.Fprintf(buf, ".TP\n.B %s\nSee \\fB%s\\fR.\n", roffEscape(name),
+				strings.Join(envs[name], "\\fR, \\fB"))
+		}
+	}
+
+	c.genManFooter(buf, page)
+}
+
+func (c *Config) genFileManPage(buf *bytes.Buffer, page ManPage) {
+	c.genManHeader(buf, page, "the configuration file of "+page.Name)
+
+	buf.WriteString(".SH DESCRIPTION\n")
+	if page.Description != "" {
+		fmt.Fprintf(buf, "%s\n", roffEscape(page.Description))
+	} else {
+		fmt.Fprintf(buf, "The options are grouped by the sections named by the groups, "+
+			"and the options of the default group are in the section \\fB[%s]\\fR.\n",
+			roffEscape(c.GetDefaultGroupName()))
+	}
+
+	buf.WriteString(".SH OPTIONS\n")
+	genGroups := func(cmd *Command) {
+		for _, ug := range c.getCommandUsageGroups(cmd, false) {
+			section := ug.Name
+			if section == "" {
+				section = c.GetDefaultGroupName()
+			}
+			if cmd == nil {
+				fmt.Fprintf(buf, ".SS \"[%s]\"\n", roffEscape(section))
+			} else {
+				fmt.Fprintf(buf, ".SS \"[%s] of the command %s\"\n", roffEscape(section),
+					roffEscape(cmd.FullName()))
+			}
+
+			for _, uo := range ug.Opts {
+				genManOpt(buf, roffEscape(uo.Name), uo)
+			}
+		}
+	}
+
+	genGroups(nil)
+	for _, cmd := range allCommands(c.commands) {
+		genGroups(cmd)
+	}
+
+	c.genManFooter(buf, page)
+}
+
+// genManFiles generates the section FILES by the file parsers.
+func (c *Config) genManFiles(buf *bytes.Buffer) {
+	var files bytes.Buffer
+	style := c.getCliStyle()
+	group := c.getGroupByName(c.GetDefaultGroupName(), false)
+	for _, parser := range c.parsers {
+		fp, ok := parser.(FileParser)
+		if !ok {
+			continue
+		}
+
+		flag := roffEscape(style.prefix + cliOptName(c, c.GetDefaultGroupName(), fp.FileOpt(), style.utoh))
+		format := roffEscape(fp.FileFormat())
+
+		var filename string
+		if c.Parsed() {
+			filename = c.StringD(fp.FileOpt(), "")
+		} else if group != nil {
+			if opt, ok := group.opts[fp.FileOpt()]; ok {
+				if v, ok := opt.opt.Default().(string); ok {
+					filename = v
+				}
+			}
+		}
+
+		if filename == "" {
+			fmt.Fprintf(&files, ".TP\n\\fB%s\\fR \\fIFILE\\fR\nThe %s config file.\n", flag, format)
+			continue
+		}
+
+		for _, file := range c.ProfileFiles(filename) {
+			fmt.Fprintf(&files, ".TP\n.I %s\nThe %s config file given by \\fB%s\\fR.\n",
+				roffEscape(file), format, flag)
+		}
+	}
+
+	if files.Len() > 0 {
+		buf.WriteString(".SH FILES\n")
+		buf.Write(files.Bytes())
+	}
+}
+
+func genManOpt(buf *bytes.Buffer, name string, uo UsageOpt) {
+	fmt.Fprintf(buf, ".TP\n\\fB%s\\fR", name)
+	if uo.Type != "" {
+		fmt.Fprintf(buf, " \\fI%s\\fR", roffEscape(uo.Type))
+	}
+	buf.WriteString("\n")
+
+	if uo.Help != "" {
+		fmt.Fprintf(buf, "%s\n", roffEscape(uo.Help))
+	}
+	if uo.Default != "" {
+		fmt.Fprintf(buf, ".br\nDefault: %s\n", roffEscape(uo.Default))
+	}
+	if len(uo.EnvVars) > 0 {
+		fmt.Fprintf(buf, ".br\nEnvironment: %s\n", roffEscape(strings.Join(uo.EnvVars, ", ")))
+	}
+	if len(uo.Constraints) > 0 {
+		fmt.Fprintf(buf, ".br\nConstraints: %s\n", roffEscape(strings.Join(uo.Constraints, ", ")))
+	}
+	if uo.Required {
+		buf.WriteString(".br\nRequired.\n")
+	}
+	if uo.Deprecated != "" {
+		fmt.Fprintf(buf, ".br\nDeprecated: %s\n", roffEscape(uo.Deprecated))
+	}
+}
+
+// getCommandUsageGroups returns the options of the command, or the global
+// options if cmd is nil, whether or not the command is selected.
+func (c *Config) getCommandUsageGroups(cmd *Command, cli bool) []UsageGroup {
+	var ugs []UsageGroup
+	for _, group := range c.AllGroups() {
+		opts := group.commandOpts(cmd, cli)
+		if len(opts) == 0 {
+			continue
+		}
+
+		ug := UsageGroup{Name: group.FullName()}
+		if ug.Name == c.GetDefaultGroupName() {
+			ug.Name = ""
+		}
+		for _, opt := range opts {
+			ug.Opts = append(ug.Opts, c.getUsageOpt(group, opt))
+		}
+		ugs = append(ugs, ug)
+	}
+
+	sort.Slice(ugs, func(i, j int) bool { return ugs[i].Name < ugs[j].Name })
+	return ugs
+}
+
+// commandOpts returns the options of the command sorted by the name, or the
+// global options if cmd is nil. If cli is true, only return the CLI options.
+func (g *OptGroup) commandOpts(cmd *Command, cli bool) []Opt {
+	var opts []Opt
+	seen := make(map[*option]bool, len(g.opts))
+	add := func(o *option) {
+		if !seen[o] && o.cmd == cmd && (!cli || o.isCli) {
+			seen[o] = true
+			opts = append(opts, o.opt)
+		}
+	}
+
+	for _, o := range g.opts {
+		add(o)
+	}
+	for _, alts := range g.alts {
+		for _, o := range alts {
+			add(o)
+		}
+	}
+
+	sort.Slice(opts, func(i, j int) bool { return opts[i].Name() < opts[j].Name() })
+	return opts
+}
+
+// allCommands returns the commands and all their sub-commands in depth-first order.
+func allCommands(cmds []*Command) []*Command {
+	var all []*Command
+	for _, cmd := range cmds {
+		all = append(all, cmd)
+		all = append(all, allCommands(cmd.cmds)...)
+	}
+	return all
+}
+
+// roffEscape escapes the text for roff.
+func roffEscape(s string) string {
+	s = strings.Replace(s, `\`, `\e`, -1)
+	s = strings.Replace(s, "-", `\-`, -1)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
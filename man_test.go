@@ -0,0 +1,56 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenManPage(t *testing.T) {
+	conf := NewConfig().AddParser(NewGetoptCliParser(true), NewEnvVarParser("app"),
+		NewSimpleIniParser("config-file"))
+	conf.SetVersion("1.2.3")
+	conf.RegisterCliOpt("", StrOpt("m", "mode", "dev", "the running mode"))
+	conf.RegisterOpt("db", Str("url", "", "the url of the database"))
+	conf.NewCommand("serve", "Start the server").
+		RegisterCliOpt("", Int("port", 80, "the port to listen on"))
+
+	buf := bytes.NewBuffer(nil)
+	page := ManPage{Name: "app", Date: "2017", Short: "the app", SeeAlso: []string{"app(5)"}}
+	if err := conf.GenManPage(buf, page); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		`.TH "APP" "1" "2017" "app 1.2.3" "User Commands"`,
+		"app \\- the app\n",
+		".TP\n\\fB\\-m, \\-\\-mode\\fR \\fIstring\\fR\nthe running mode\n.br\nDefault: dev\n.br\nEnvironment: APP_MODE\n",
+		".SS \"serve\"\nStart the server\n.TP\n\\fB\\-\\-port\\fR \\fIint\\fR\n",
+		".SH ENVIRONMENT\n.TP\n.B APP_MODE\nSee \\fB\\-m, \\-\\-mode\\fR.\n",
+		".SH FILES\n.TP\n\\fB\\-\\-config\\-file\\fR \\fIFILE\\fR\nThe ini config file.\n",
+		".SH \"SEE ALSO\"\napp(5)\n",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("no '%s' in the man page:\n%s", s, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "url") {
+		t.Errorf("the non-CLI option is in the man page:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	page.Section = 5
+	if err := conf.GenManPage(buf, page); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`.TH "APP" "5" "2017" "app 1.2.3" "File Formats"`,
+		".SS \"[DEFAULT]\"\n.TP\n\\fBmode\\fR \\fIstring\\fR\n",
+		".SS \"[db]\"\n.TP\n\\fBurl\\fR \\fIstring\\fR\nthe url of the database\n",
+		".SS \"[DEFAULT] of the command serve\"\n.TP\n\\fBport\\fR \\fIint\\fR\n",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("no '%s' in the man page:\n%s", s, buf.String())
+		}
+	}
+}
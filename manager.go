@@ -25,11 +25,16 @@ limitations under the License.
 package config
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 )
 
@@ -70,6 +75,51 @@ type Config struct {
 	watch      func(string, string, interface{})
 	groups     map[string]*OptGroup
 	validators []func() error
+
+	commands []*Command
+	command  *Command // The selected command
+
+	argGroup *OptGroup
+	argOpts  []*option
+
+	layers map[string]int // The mapping from the layer name to the priority
+
+	verifier  FileVerifier
+	tmplFuncs template.FuncMap
+
+	profiles   []string
+	profileOpt string
+	respDepth  int
+
+	expandLookup func(string) (string, bool)
+
+	usageTmpl   *template.Template
+	usageWriter io.Writer
+
+	completionWriter io.Writer
+
+	versionHandler func(*Config, string) error
+	helpHandler    func(*Config) error
+
+	promptIn     io.Reader
+	promptOut    io.Writer
+	promptReader *bufio.Reader
+
+	slock   sync.Mutex
+	status  map[string]*ParserStatus
+	current string // The name of the parser which is parsing.
+
+	changes         func([]Change)
+	stateValidators []func(*Snapshot) error
+	txnLock         sync.Mutex
+
+	reloadLock sync.Mutex
+	reloading  bool // Whether it is the staging copy for Reload
+
+	snapshot    atomic.Value // *Snapshot
+	snapLock    sync.Mutex
+	snapVersion uint64
+	snapPaused  int32 // Not to publish the snapshot on each change if 1
 }
 
 // NewConfig returns a new Config.
@@ -82,6 +132,13 @@ func NewConfig() *Config {
 		isRequired: true,
 		groupName:  DefaultGroupName,
 		groups:     make(map[string]*OptGroup, 2),
+		status:     make(map[string]*ParserStatus, 4),
+		layers: map[string]int{
+			LayerCli:     0,
+			LayerEnv:     10,
+			LayerFile:    100,
+			LayerDefault: 1000,
+		},
 	}
 	return conf.SetGroupSeparator(".")
 }
@@ -211,37 +268,53 @@ func (c *Config) Parsed() bool {
 // After parsing a certain option, it will call the validators of the option
 // to validate whether the option value is valid.
 //
+// If the first argument is CompleteCommand, it outputs the completion candidates
+// and returns ErrCompletion. See Config.Complete. If the CLI parser meets
+// the version or help option, it returns ErrVersion or ErrHelp after printing
+// them. So the caller should exit the program for these errors.
+//
 // If parsed, it will panic when calling it.
 func (c *Config) Parse(args ...string) (err error) {
 	c.panicIsParsed(true)
 	c.getGroupByName(c.groupName, true) // Ensure that the default group exists.
 
+	// Publish the snapshot only once after parsing.
+	atomic.StoreInt32(&c.snapPaused, 1)
+	defer func() {
+		atomic.StoreInt32(&c.snapPaused, 0)
+		c.publishSnapshot()
+	}()
+
 	if args == nil {
 		c.cliArgs = os.Args[1:]
 	} else {
 		c.cliArgs = args
 	}
 
-	for _, parser := range c.parsers {
-		c.debug("Initializing the parser '%s'", parser.Name())
-		if err = parser.Pre(c); err != nil {
+	if c.respDepth > 0 {
+		if c.cliArgs, err = ExpandResponseFiles(c.cliArgs, c.respDepth); err != nil {
 			return err
 		}
 	}
 
+	if err = c.initParsers(); err != nil {
+		return err
+	}
+
+	// Output the completion candidates for the completion scripts.
+	if len(c.cliArgs) > 0 && c.cliArgs[0] == CompleteCommand {
+		c.printCompletion(c.cliArgs[1:])
+		return ErrCompletion
+	}
+
 	c.parsed = true
-	for _, parser := range c.parsers {
-		c.debug("Calling the parser '%s'", parser.Name())
-		if err = parser.Parse(c); err != nil {
-			return fmt.Errorf("The '%s' parser failed: %s", parser.Name(), err)
-		}
+	if err = c.callParsers(); err != nil {
+		return err
 	}
 
-	for _, parser := range c.parsers {
-		c.debug("Cleaning the parser '%s'", parser.Name())
-		if err = parser.Post(c); err != nil {
-			return err
-		}
+	// Assign the rest CLI arguments to the positional arguments.
+	if err = c.parseArgs(); err != nil {
+		return err
 	}
 
 	// Check whether all the groups have parsed all the required options.
@@ -257,6 +330,51 @@ func (c *Config) Parse(args ...string) (err error) {
 		}
 	}
 
+	if len(c.stateValidators) > 0 {
+		if err = c.validateState(c.buildSnapshot(0)); err != nil {
+			return err
+		}
+	}
+
+	return
+}
+
+// initParsers calls the Pre method of all the parsers.
+func (c *Config) initParsers() (err error) {
+	for _, parser := range c.parsers {
+		c.debug("Initializing the parser '%s'", parser.Name())
+		if err = parser.Pre(c); err != nil {
+			c.updateParserStatus(parser.Name(), 0, err)
+			return err
+		}
+	}
+	return
+}
+
+// callParsers calls the Parse method of all the parsers, then the Post method.
+func (c *Config) callParsers() (err error) {
+	for _, parser := range c.parsers {
+		c.debug("Calling the parser '%s'", parser.Name())
+		c.setCurrentParser(parser.Name())
+		err = parser.Parse(c)
+		c.setCurrentParser("")
+		c.updateParserStatus(parser.Name(), 0, err)
+		switch err {
+		case nil:
+		case ErrVersion, ErrHelp:
+			return err
+		default:
+			return fmt.Errorf("The '%s' parser failed: %s", parser.Name(), err)
+		}
+	}
+
+	for _, parser := range c.parsers {
+		c.debug("Cleaning the parser '%s'", parser.Name())
+		if err = parser.Post(c); err != nil {
+			c.updateParserStatus(parser.Name(), 0, err)
+			return err
+		}
+	}
 	return
 }
 
@@ -266,7 +384,8 @@ func (c *Config) Parse(args ...string) (err error) {
 // SetVersion sets the version information.
 //
 // If the CLI parser support the version function, it will print the version
-// and exit when giving the CLI option version.
+// by the version handler and return ErrVersion when giving the CLI option
+// version. See SetVersionHandler and GetVersionInfo.
 //
 // It supports:
 //     SetVersion(version)             // SetVersion("1.0.0")
@@ -309,6 +428,8 @@ func (c *Config) CliArgs() []string {
 // Args returns the rest of the CLI arguments, which are not the options
 // starting with the prefix "-", "--" or others, etc.
 //
+// For the named and typed positional arguments, see RegisterArg.
+//
 // Notice: you should not modify the returned string slice result.
 //
 // If not parsed, it will panic when calling it.
@@ -396,6 +517,15 @@ func (c *Config) HasParser(name string) bool {
 //
 // The tag of the field supports "name", "short", "default", "help", which are
 // equal to the name, the short name, the default, the help of the option.
+// The tag "env" declares the explicit names of the environment variables
+// separated by the comma, such as `env:"DATABASE_URL,DB_URL"`. See EnvVarOpt.
+// The tag "expand" decides whether to expand the variables in the value from
+// the config file, which supports the same values as the tag "cli".
+// See EnableFileExpansion. The tag "required" decides whether the option must be
+// given by a parser, which supports the same values as the tag "cli", and
+// the tag "deprecated" is the deprecation message. See RequiredOpt and
+// DeprecatedOpt. The tag "sensitive" decides whether the value is sensitive,
+// which supports the same values as the tag "cli". See SensitiveOpt.
 // If you want to ignore a certain field, just set the tag "name" to "-",
 // such as `name:"-"`. The field also contains the tag "cli", whose value maybe
 // "1", "t", "T", "on", "On", "ON", "true", "True", "TRUE", and which represents
@@ -422,20 +552,25 @@ func (c *Config) HasParser(name string) bool {
 // Notice: For the struct option, you shouldn't call SetOptValue()
 // because of concurrence.
 func (c *Config) RegisterStruct(group string, s interface{}) {
-	c.registerStruct(group, s, false)
+	c.registerStruct(nil, group, s, false)
 }
 
 // RegisterCliStruct is the same as RegisterStruct, but it will register
 // the option into the CLI parser by default.
 func (c *Config) RegisterCliStruct(group string, s interface{}) {
-	c.registerStruct(group, s, true)
+	c.registerStruct(nil, group, s, true)
 }
 
-func (c *Config) registerStruct(group string, s interface{}, cli bool) {
+func (c *Config) registerStruct(cmd *Command, group string, s interface{}, cli bool) {
 	c.panicIsParsed(true)
-	c.getGroupByName(strings.Trim(group, c.groupSep), true).registerStruct(s, cli)
+	c.getGroupByName(strings.Trim(group, c.groupSep), true).registerStruct(cmd, s, cli)
 	if v, ok := s.(StructValidator); ok {
-		c.validators = append(c.validators, v.Validate)
+		c.validators = append(c.validators, func() error {
+			if c.isActiveCommand(cmd) {
+				return v.Validate()
+			}
+			return nil
+		})
 	}
 }
 
@@ -448,7 +583,7 @@ func (c *Config) registerStruct(group string, s interface{}, cli bool) {
 //
 // If parsed, it will panic when calling it.
 func (c *Config) RegisterCliOpt(group string, opt Opt) {
-	c.registerOpt(group, true, opt)
+	c.registerOpt(nil, group, true, opt)
 }
 
 // RegisterCliOpts registers the options into the group.
@@ -473,7 +608,7 @@ func (c *Config) RegisterCliOpts(group string, opts []Opt) {
 //
 // If parsed, it will panic when calling it.
 func (c *Config) RegisterOpt(group string, opt Opt) {
-	c.registerOpt(group, false, opt)
+	c.registerOpt(nil, group, false, opt)
 }
 
 // RegisterOpts registers the options into the group.
@@ -493,13 +628,23 @@ func (c *Config) RegisterOpts(group string, opts []Opt) {
 //
 // If the group name is "", it's regarded as the default group.
 //
-// The first argument, cli, indicates whether the option is as the CLI option,
+// The first argument, cmd, is the command which the option belongs to,
+// and nil represents the global option.
+//
+// The second argument, cli, indicates whether the option is as the CLI option,
 // too.
 //
-// If parsed, it will panic when calling it.
-func (c *Config) registerOpt(group string, cli bool, opt Opt) {
+// If parsed, it will panic when calling it, except that the option has been
+// registered when reloading, such as by the Pre method of the parser.
+func (c *Config) registerOpt(cmd *Command, group string, cli bool, opt Opt) {
+	if c.reloading && opt != nil {
+		if g := c.getGroupByName(group, false); g != nil && g.HasOpt(opt.Name()) {
+			return
+		}
+	}
+
 	c.panicIsParsed(true)
-	c.getGroupByName(group, true).registerOpt(cli, opt)
+	c.getGroupByName(group, true).registerOpt(cmd, cli, opt)
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -520,17 +665,40 @@ func (c *Config) Observe(f func(groupName string, optName string, optValue inter
 //
 // priority it should be the priority of the parser. It only set the option value
 // successfully for the priority higher than the last. So you can use 0
-// to update it coercively.
+// to update it coercively. But the value of the lower priority is also kept
+// in the layer stack of the option, which is revealed when the higher ones
+// are unset. See Layer and UnsetOptValue.
+//
+// After parsing, the value is not set and an error is returned if the state
+// validators fail against the state with the new value, which is serialized
+// with the transactions. See RegisterStateValidator and Begin.
 //
 // Notice: You cannot call SetOptValue() for the struct option, because we have
 // no way to promise that it's thread-safe.
 func (c *Config) SetOptValue(priority int, groupName, optName string, optValue interface{}) error {
-	if priority < 0 {
+	return c.setOptValue(c.newLayer(priority, optValue, Origin{}), groupName, optName, false)
+}
+
+// SetSecretOptValue is the same as SetOptValue, but the value is a secret,
+// which will not be output into the debug log.
+func (c *Config) SetSecretOptValue(priority int, groupName, optName string, optValue interface{}) error {
+	return c.setOptValue(c.newLayer(priority, optValue, Origin{}), groupName, optName, true)
+}
+
+// SetOptValueWithOrigin is the same as SetOptValue, but records the origin
+// of the value in the source, such as the file and the line. See Explain.
+func (c *Config) SetOptValueWithOrigin(priority int, groupName, optName string,
+	optValue interface{}, origin Origin) error {
+	return c.setOptValue(c.newLayer(priority, optValue, origin), groupName, optName, false)
+}
+
+func (c *Config) setOptValue(layer Layer, groupName, optName string, secret bool) error {
+	if layer.Priority < 0 {
 		return fmt.Errorf("the priority must not be the negative")
 	}
 
 	if group := c.getGroupByName(groupName, false); group != nil {
-		return group.setOptValue(priority, optName, optValue)
+		return group.setLayerValue(layer, optName, secret)
 	}
 	return fmt.Errorf("no group '%s'", groupName)
 }
@@ -580,12 +748,13 @@ func (c *Config) printMap(parent string, ms map[string]interface{}, indent strin
 }
 
 // Groups is the same as AllGroups, except those groups that have no options,
-// which are the assistant groups.
+// which are the assistant groups, or only have the options of the commands
+// that are not selected.
 func (c *Config) Groups() []*OptGroup {
 	// c.panicIsParsed(false)
 	groups := make([]*OptGroup, 0, len(c.groups))
 	for _, group := range c.groups {
-		if len(group.opts) > 0 {
+		if group.hasActiveOpts() {
 			groups = append(groups, group)
 		}
 	}
@@ -1049,3 +1218,18 @@ func (c *Config) TimesD(name string, _default []time.Time) []time.Time {
 func (c *Config) Times(name string) []time.Time {
 	return c.Group("").Times(name)
 }
+
+// StringMapE is equal to c.Group("").StringMapE(name).
+func (c *Config) StringMapE(name string) (map[string]string, error) {
+	return c.Group("").StringMapE(name)
+}
+
+// StringMapD is equal to c.Group("").StringMapD(name, _default).
+func (c *Config) StringMapD(name string, _default map[string]string) map[string]string {
+	return c.Group("").StringMapD(name, _default)
+}
+
+// StringMap is equal to c.Group("").StringMap(name).
+func (c *Config) StringMap(name string) map[string]string {
+	return c.Group("").StringMap(name)
+}
@@ -64,6 +64,29 @@ func ExampleNewEnvVarParser() {
 	// var2=123
 }
 
+func ExampleConfig_Status() {
+	os.Setenv("STATUS_OPT1", "abc")
+	os.Setenv("STATUS_OPT2", "123")
+
+	conf := NewConfig().AddParser(NewEnvVarParser("status"))
+	conf.RegisterOpt("", Str("opt1", "", "the option 1"))
+	conf.RegisterOpt("", Int("opt2", 0, "the option 2"))
+	conf.RegisterOpt("", Str("opt3", "", "the option 3"))
+
+	if err := conf.Parse(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, status := range conf.Status() {
+		fmt.Printf("name=%s, priority=%d, values=%d, ok=%t, err=%v\n", status.Name,
+			status.Priority, status.Values, !status.LastSuccess.IsZero(), status.LastError)
+	}
+
+	// Output:
+	// name=env, priority=10, values=2, ok=true, err=<nil>
+}
+
 func ExampleConfig() {
 	cliOpts1 := []Opt{
 		StrOpt("", "required", "", "required").SetValidators(NewStrLenValidator(1, 10)),
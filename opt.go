@@ -50,6 +50,195 @@ type Opt interface {
 	Parse(interface{}) (interface{}, error)
 }
 
+// EnvVarOpt is an optional interface of Opt, which declares the explicit
+// names of the environment variables of the option, such as "DATABASE_URL"
+// or "PORT" injected by the platform.
+//
+// The explicit names take precedence over the name generated by the
+// environment variable parser, and the former name takes precedence over
+// the latter. Notice: the explicit names are not added the prefix.
+type EnvVarOpt interface {
+	Opt
+
+	// EnvVars returns the explicit names of the environment variables.
+	EnvVars() []string
+}
+
+// GetEnvVars returns the explicit names of the environment variables
+// of the option if it has implemented the interface EnvVarOpt, or nil.
+func GetEnvVars(opt Opt) []string {
+	if o, ok := opt.(EnvVarOpt); ok {
+		return o.EnvVars()
+	}
+	return nil
+}
+
+// CounterOpt is an optional interface of Opt, which reports whether the int
+// option is a counter, such as the verbosity "-vvv", the value of which is
+// the number of the occurrences on the CLI.
+type CounterOpt interface {
+	Opt
+
+	// IsCounter reports whether the option is a counter.
+	IsCounter() bool
+}
+
+// IsCounterOpt reports whether the option is a counter. See CounterOpt.
+func IsCounterOpt(opt Opt) bool {
+	if o, ok := opt.(CounterOpt); ok {
+		return o.IsCounter()
+	}
+	return false
+}
+
+// RequiredOpt is an optional interface of Opt, which reports whether
+// the option must be given by a parser, even if it has a default value.
+type RequiredOpt interface {
+	Opt
+
+	// IsRequired reports whether the option is required.
+	IsRequired() bool
+}
+
+// IsRequiredOpt reports whether the option is required. See RequiredOpt.
+func IsRequiredOpt(opt Opt) bool {
+	if o, ok := opt.(RequiredOpt); ok {
+		return o.IsRequired()
+	}
+	return false
+}
+
+// DeprecatedOpt is an optional interface of Opt, which reports the deprecation
+// message of the option, such as "use --listen instead".
+type DeprecatedOpt interface {
+	Opt
+
+	// Deprecated returns the deprecation message, or "" if not deprecated.
+	Deprecated() string
+}
+
+// GetDeprecated returns the deprecation message of the option if it has
+// implemented the interface DeprecatedOpt, or "".
+func GetDeprecated(opt Opt) string {
+	if o, ok := opt.(DeprecatedOpt); ok {
+		return o.Deprecated()
+	}
+	return ""
+}
+
+// FileHintOpt is an optional interface of Opt, which reports whether the value
+// of the option is a file path, which is used by the shell completion.
+type FileHintOpt interface {
+	Opt
+
+	// FileHint reports whether the value is a file path, and the allowed
+	// extensions without the leading dot, such as "ini". If exts is empty,
+	// any file is allowed.
+	FileHint() (ok bool, exts []string)
+}
+
+// CompleterOpt is an optional interface of Opt, which computes the completion
+// candidates of the value by the program itself, such as the names from
+// the database.
+type CompleterOpt interface {
+	Opt
+
+	// Complete returns the candidates of the value starting with prefix.
+	Complete(prefix string) []string
+}
+
+// SensitiveOpt is an optional interface of Opt, which reports whether
+// the value of the option is sensitive, such as the password, which is
+// masked in the log and read without the echo when prompting.
+type SensitiveOpt interface {
+	Opt
+
+	// IsSensitive reports whether the value of the option is sensitive.
+	IsSensitive() bool
+}
+
+// IsSensitiveOpt reports whether the option is sensitive. See SensitiveOpt.
+func IsSensitiveOpt(opt Opt) bool {
+	if o, ok := opt.(SensitiveOpt); ok {
+		return o.IsSensitive()
+	}
+	return false
+}
+
+// EnvVarSetter is an optional interface of Opt, which sets the explicit names
+// of the environment variables of the option. See EnvVarOpt.
+//
+// The builtin options, such as Str and Int, have implemented it and the other
+// setter interfaces below, which are used by asserting the option. For example,
+//
+//    Str("url", "", "the url").(EnvVarSetter).SetEnvVars("DATABASE_URL")
+type EnvVarSetter interface {
+	Opt
+
+	// SetEnvVars sets the explicit names of the environment variables,
+	// and returns the option itself.
+	SetEnvVars(names ...string) ValidatorChainOpt
+}
+
+// NoExpandSetter is an optional interface of Opt, which sets whether not to
+// expand the variables in the value from the config file. See NoExpandOpt.
+type NoExpandSetter interface {
+	Opt
+
+	// SetNoExpand sets whether not to expand the value, and returns
+	// the option itself.
+	SetNoExpand(noExpand bool) ValidatorChainOpt
+}
+
+// RequiredSetter is an optional interface of Opt, which sets whether
+// the option must be given by a parser, not the default. See RequiredOpt.
+type RequiredSetter interface {
+	Opt
+
+	// SetRequired sets whether the option is required, and returns
+	// the option itself.
+	SetRequired(required bool) ValidatorChainOpt
+}
+
+// DeprecatedSetter is an optional interface of Opt, which sets
+// the deprecation message of the option. See DeprecatedOpt.
+type DeprecatedSetter interface {
+	Opt
+
+	// SetDeprecated sets the deprecation message, and returns the option
+	// itself. "" represents that the option is not deprecated.
+	SetDeprecated(msg string) ValidatorChainOpt
+}
+
+// SensitiveSetter is an optional interface of Opt, which sets whether
+// the value of the option is sensitive. See SensitiveOpt.
+type SensitiveSetter interface {
+	Opt
+
+	// SetSensitive sets whether the value is sensitive, and returns
+	// the option itself.
+	SetSensitive(sensitive bool) ValidatorChainOpt
+}
+
+// FileHintSetter is an optional interface of Opt, which sets that the value
+// of the option is a file path. See FileHintOpt.
+type FileHintSetter interface {
+	Opt
+
+	// SetFileHint sets that the value is a file path with one of
+	// the extensions, and returns the option itself.
+	SetFileHint(exts ...string) ValidatorChainOpt
+}
+
+// CompleterSetter is an optional interface of Opt, which sets the function
+// to compute the completion candidates of the value. See CompleterOpt.
+type CompleterSetter interface {
+	Opt
+
+	// SetCompleter sets the completion function, and returns the option itself.
+	SetCompleter(complete func(prefix string) []string) ValidatorChainOpt
+}
+
 type optType int
 
 func (ot optType) String() string {
@@ -83,6 +272,8 @@ const (
 	float64sType
 	durationsType
 	timesType
+
+	stringMapType
 )
 
 var optTypeMap = map[optType]string{
@@ -112,6 +303,8 @@ var optTypeMap = map[optType]string{
 	float64sType:  "[]float64",
 	durationsType: "[]time.Duration",
 	timesType:     "[]time.Time",
+
+	stringMapType: "map[string]string",
 }
 
 var kind2optType = map[reflect.Kind]optType{
@@ -157,6 +350,8 @@ func getOptType(v reflect.Value) optType {
 		return durationsType
 	case []time.Time:
 		return timesType
+	case map[string]string:
+		return stringMapType
 	default:
 		panic(fmt.Errorf("doesn't support the type %s", v.Type().Name()))
 	}
@@ -170,9 +365,30 @@ type baseOpt struct {
 
 	_type      optType
 	validators []Validator
+
+	envs     []string
+	noExpand bool
+	counter  bool
+
+	required   bool
+	deprecated string
+	sensitive  bool
+
+	fileHint  bool
+	fileExts  []string
+	completer func(prefix string) []string
 }
 
-var _ ValidatorChainOpt = baseOpt{}
+var (
+	_ ValidatorChainOpt = baseOpt{}
+	_ EnvVarSetter      = baseOpt{}
+	_ NoExpandSetter    = baseOpt{}
+	_ RequiredSetter    = baseOpt{}
+	_ DeprecatedSetter  = baseOpt{}
+	_ SensitiveSetter   = baseOpt{}
+	_ FileHintSetter    = baseOpt{}
+	_ CompleterSetter   = baseOpt{}
+)
 
 func newBaseOpt(short, name string, _default interface{}, help string,
 	optType optType) baseOpt {
@@ -211,6 +427,96 @@ func (o baseOpt) GetValidators() []Validator {
 	return o.validators
 }
 
+// SetEnvVars sets the explicit names of the environment variables.
+func (o baseOpt) SetEnvVars(names ...string) ValidatorChainOpt {
+	o.envs = names
+	return o
+}
+
+// EnvVars returns the explicit names of the environment variables.
+func (o baseOpt) EnvVars() []string {
+	return o.envs
+}
+
+// SetNoExpand sets whether not to expand the variables in the value from
+// the config file, which is false by default. See EnableFileExpansion.
+func (o baseOpt) SetNoExpand(noExpand bool) ValidatorChainOpt {
+	o.noExpand = noExpand
+	return o
+}
+
+// NoExpand reports whether not to expand the variables in the value.
+func (o baseOpt) NoExpand() bool {
+	return o.noExpand
+}
+
+// SetRequired sets whether the option must be given by a parser.
+func (o baseOpt) SetRequired(required bool) ValidatorChainOpt {
+	o.required = required
+	return o
+}
+
+// IsRequired reports whether the option must be given by a parser.
+func (o baseOpt) IsRequired() bool {
+	return o.required
+}
+
+// SetDeprecated sets the deprecation message of the option.
+func (o baseOpt) SetDeprecated(msg string) ValidatorChainOpt {
+	o.deprecated = msg
+	return o
+}
+
+// Deprecated returns the deprecation message of the option.
+func (o baseOpt) Deprecated() string {
+	return o.deprecated
+}
+
+// SetSensitive sets whether the value of the option is sensitive.
+// See SensitiveOpt.
+func (o baseOpt) SetSensitive(sensitive bool) ValidatorChainOpt {
+	o.sensitive = sensitive
+	return o
+}
+
+// IsSensitive reports whether the value of the option is sensitive.
+func (o baseOpt) IsSensitive() bool {
+	return o.sensitive
+}
+
+// SetFileHint marks that the value of the option is a file path with one of
+// the extensions, which is used by the shell completion. See FileHintOpt.
+func (o baseOpt) SetFileHint(exts ...string) ValidatorChainOpt {
+	o.fileHint = true
+	o.fileExts = exts
+	return o
+}
+
+// FileHint reports whether the value is a file path with the extensions.
+func (o baseOpt) FileHint() (ok bool, exts []string) {
+	return o.fileHint, o.fileExts
+}
+
+// SetCompleter sets the function to compute the completion candidates of
+// the value. See CompleterOpt.
+func (o baseOpt) SetCompleter(complete func(prefix string) []string) ValidatorChainOpt {
+	o.completer = complete
+	return o
+}
+
+// Complete returns the completion candidates of the value starting with prefix.
+func (o baseOpt) Complete(prefix string) []string {
+	if o.completer == nil {
+		return nil
+	}
+	return o.completer(prefix)
+}
+
+// IsCounter reports whether the option is a counter. See CountOpt.
+func (o baseOpt) IsCounter() bool {
+	return o.counter
+}
+
 // GetName returns the name of the option.
 func (o baseOpt) Name() string {
 	return o.name
@@ -281,6 +587,8 @@ func (o baseOpt) Default() interface{} {
 		return o._default.([]uint64)
 	case float64sType:
 		return o._default.([]float64)
+	case stringMapType:
+		return o._default.(map[string]string)
 	default:
 		panic(fmt.Errorf("don't support the type %s", o._type))
 	}
@@ -337,6 +645,8 @@ func (o baseOpt) Zero() interface{} {
 		return []time.Duration{}
 	case timesType:
 		return []time.Time{}
+	case stringMapType:
+		return map[string]string{}
 	default:
 		panic(fmt.Errorf("don't support the type %s", o._type))
 	}
@@ -413,6 +723,8 @@ func parseOpt(data interface{}, _type optType) (v interface{}, err error) {
 		return ToDurations(data)
 	case timesType:
 		return ToTimes(time.RFC3339Nano, data)
+	case stringMapType:
+		return ToStringMap(data)
 	default:
 		err = fmt.Errorf("don't support the type '%s'", _type)
 	}
@@ -547,7 +859,7 @@ func TimesOpt(short, name string, _default []time.Time, help string) ValidatorCh
 
 // StringsOpt return a new []string option.
 func StringsOpt(short, name string, _default []string, help string) ValidatorChainOpt {
-	return newBaseOpt(short, name, _default, help, float64sType)
+	return newBaseOpt(short, name, _default, help, stringsType)
 }
 
 // IntsOpt return a new []int option.
@@ -575,6 +887,23 @@ func Float64sOpt(short, name string, _default []float64, help string) ValidatorC
 	return newBaseOpt(short, name, _default, help, float64sType)
 }
 
+// StringMapOpt return a new map[string]string option.
+//
+// For the string value, the key-value pairs are separated by the comma,
+// and the key and the value are separated by the equal sign,
+// such as "k1=v1,k2=v2".
+func StringMapOpt(short, name string, _default map[string]string, help string) ValidatorChainOpt {
+	return newBaseOpt(short, name, _default, help, stringMapType)
+}
+
+// CountOpt returns a new int counter option, the value of which is the number
+// of the occurrences on the CLI, such as "-v -v" or "-vv". See CounterOpt.
+func CountOpt(short, name string, help string) ValidatorChainOpt {
+	o := newBaseOpt(short, name, nil, help, intType)
+	o.counter = true
+	return o
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 // Bool is equal to BoolOpt("", name, _default, help).
@@ -696,3 +1025,13 @@ func Uint64s(name string, _default []uint64, help string) ValidatorChainOpt {
 func Float64s(name string, _default []float64, help string) ValidatorChainOpt {
 	return newBaseOpt("", name, _default, help, float64sType)
 }
+
+// StringMap is equal to StringMapOpt("", name, _default, help).
+func StringMap(name string, _default map[string]string, help string) ValidatorChainOpt {
+	return newBaseOpt("", name, _default, help, stringMapType)
+}
+
+// Count is equal to CountOpt("", name, help).
+func Count(name string, help string) ValidatorChainOpt {
+	return CountOpt("", name, help)
+}
@@ -22,6 +22,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -75,9 +78,38 @@ type Parser interface {
 	Post(*Config) error
 }
 
+// FileParser is an optional interface of Parser, which reads the config file
+// given by an option, such as the man page to list the config files.
+type FileParser interface {
+	Parser
+
+	// FileFormat returns the format of the config file, such as "ini".
+	FileFormat() string
+
+	// FileOpt returns the name of the option in the default group,
+	// the value of which is the path of the config file.
+	FileOpt() string
+}
+
+// cliOptName returns the name of the CLI option, which is prefixed by the group
+// name except the default group.
+//
+// If utoh is true, it will convert the underline to the hyphen.
+func cliOptName(c *Config, group, name string, utoh bool) string {
+	if group != c.GetDefaultGroupName() {
+		name = fmt.Sprintf("%s%s%s", group, c.GetGroupSeparator(), name)
+	}
+
+	if utoh {
+		name = strings.Replace(name, "_", "-", -1)
+	}
+	return name
+}
+
 type flagParser struct {
-	utoh bool
-	fset *flag.FlagSet
+	utoh  bool
+	split bool
+	fset  *flag.FlagSet
 }
 
 // NewDefaultFlagCliParser returns a new CLI parser based on flag,
@@ -98,17 +130,44 @@ func NewDefaultFlagCliParser(underlineToHyphen ...bool) Parser {
 //
 // If underlineToHyphen is true, it will convert the underline to the hyphen.
 //
+// The CLI arguments are parsed by a new flag.FlagSet with the flags of
+// flagSet, so flagSet is not changed except being marked as parsed. The help
+// option calls the help handler, which prints the usage by Config.PrintUsage
+// by default, and returns ErrHelp. The version option, such as "-version"
+// or "-version=json", prints the version by the version handler and returns
+// ErrVersion. See SetVersionHandler.
+//
+// If the first rest argument is a command, it will select the command and parse
+// the options of the command from the arguments after it by a new flag.FlagSet.
+// See Config.SelectCommand.
+//
+// The slice and map options may be given repeatedly, such as
+// "-host a -host b" and "-label k1=v1 -label k2=v2", and each value is also
+// split by the comma. The int counter option is increased by each occurrence,
+// and its one character name or short name may be repeated, such as "-vvv".
+// See CountOpt.
+//
 // Notice: when other libraries use the default global flag.FlagSet, that's
 // flag.CommandLine, such as github.com/golang/glog, please use flag.CommandLine
 // as flag.FlagSet.
 func NewFlagCliParser(flagSet *flag.FlagSet, underlineToHyphen bool) Parser {
+	return NewFlagCliParserWithOption(flagSet, CliParserOption{
+		UnderlineToHyphen: underlineToHyphen,
+		SplitComma:        true,
+	})
+}
+
+// NewFlagCliParserWithOption is the same as NewFlagCliParser, but configures
+// the parser by the option.
+func NewFlagCliParserWithOption(flagSet *flag.FlagSet, option CliParserOption) Parser {
 	if flagSet == nil {
 		flagSet = flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
 	}
 
 	return flagParser{
-		fset: flagSet,
-		utoh: underlineToHyphen,
+		fset:  flagSet,
+		utoh:  option.UnderlineToHyphen,
+		split: option.SplitComma,
 	}
 }
 
@@ -124,28 +183,47 @@ func (f flagParser) Pre(c *Config) error {
 	return nil
 }
 
+func (f flagParser) cliStyle() cliStyle {
+	return cliStyle{prefix: "-", utoh: f.utoh}
+}
+
 func (f flagParser) Post(c *Config) error {
 	return nil
 }
 
-func (f flagParser) Parse(c *Config) (err error) {
-	// Convert the option name.
-	name2group := make(map[string]string, 8)
-	name2opt := make(map[string]string, 8)
+// register registers the CLI options of the global and the selected command
+// path into fset, and returns the mappings from the flag name to the group
+// and option names, and from the one character name of the counter option
+// to the flag name.
+func (f flagParser) register(c *Config, fset *flag.FlagSet) (name2group,
+	name2opt, counters map[string]string) {
+	name2group = make(map[string]string, 8)
+	name2opt = make(map[string]string, 8)
+	counters = make(map[string]string, 2)
 	for _, group := range c.Groups() {
 		gname := group.FullName()
 		for _, opt := range group.CliOpts() {
-			name := opt.Name()
-			if gname != c.GetDefaultGroupName() {
-				name = fmt.Sprintf("%s%s%s", gname, c.GetGroupSeparator(), name)
+			name := cliOptName(c, gname, opt.Name(), f.utoh)
+			name2group[name] = gname
+			name2opt[name] = opt.Name()
+
+			help := opt.Help()
+			if envs := GetEnvVars(opt); len(envs) > 0 {
+				help = fmt.Sprintf("%s (env: %s)", help, strings.Join(envs, ", "))
 			}
 
-			if f.utoh {
-				name = strings.Replace(name, "_", "-", -1)
+			if IsCounterOpt(opt) {
+				for _, n := range []string{opt.Name(), opt.Short()} {
+					if len(n) == 1 {
+						counters[n] = name
+					}
+				}
 			}
 
-			name2group[name] = gname
-			name2opt[name] = opt.Name()
+			if IsCounterOpt(opt) {
+				fset.Var(&counterValue{}, name, help)
+				continue
+			}
 
 			switch opt.Zero().(type) {
 			case bool:
@@ -153,69 +231,125 @@ func (f flagParser) Parse(c *Config) (err error) {
 				if v := opt.Default(); v != nil {
 					_default = v.(bool)
 				}
-				f.fset.Bool(name, _default, opt.Help())
+				fset.Bool(name, _default, help)
+			case map[string]string:
+				fset.Var(&mapValue{split: f.split}, name, help)
 			case int, int8, int16, int32, int64:
 				var _default int64
 				if v := opt.Default(); v != nil {
 					_default, _ = ToInt64(v)
 				}
-				f.fset.Int64(name, _default, opt.Help())
+				fset.Int64(name, _default, help)
 			case uint, uint8, uint16, uint32, uint64:
 				var _default uint64
 				if v := opt.Default(); v != nil {
 					_default, _ = ToUint64(v)
 				}
-				f.fset.Uint64(name, _default, opt.Help())
+				fset.Uint64(name, _default, help)
 			case float32, float64:
 				var _default float64
 				if v := opt.Default(); v != nil {
 					_default, _ = ToFloat64(v)
 				}
-				f.fset.Float64(name, _default, opt.Help())
+				fset.Float64(name, _default, help)
 			case time.Duration:
 				var _default time.Duration
 				if v := opt.Default(); v != nil {
 					_default = v.(time.Duration)
 				}
-				f.fset.Duration(name, _default, opt.Help())
+				fset.Duration(name, _default, help)
 			default:
+				if isMultiOpt(opt) {
+					fset.Var(&sliceValue{split: f.split}, name, help)
+					continue
+				}
+
 				var _default string
 				if v := opt.Default(); v != nil {
 					_default = fmt.Sprintf("%v", v)
 				}
-				f.fset.String(name, _default, opt.Help())
+				fset.String(name, _default, help)
 			}
 		}
 	}
+	return
+}
+
+// newFlagSet returns a new flag set to parse the CLI arguments, which returns
+// the error instead of exiting and calls the help handler for the help option.
+func (f flagParser) newFlagSet(c *Config, name string) *flag.FlagSet {
+	fset := flag.NewFlagSet(name, flag.ContinueOnError)
+	fset.SetOutput(f.fset.Output())
+	fset.Usage = func() { c.handleHelp() }
+	return fset
+}
+
+func (f flagParser) Parse(c *Config) (err error) {
+	// Parse the CLI arguments by a new flag set with the flags of the given one,
+	// such as flag.CommandLine, so that it is not changed.
+	fset := f.newFlagSet(c, f.fset.Name())
+	f.fset.VisitAll(func(fg *flag.Flag) { fset.Var(fg.Value, fg.Name, fg.Usage) })
+	name2group, name2opt, counters := f.register(c, fset)
 
 	// Register the version option.
-	var _version *bool
-	name, version, help := c.GetVersion()
-	if name != "" {
-		_version = f.fset.Bool(name, false, help)
+	var _version versionValue
+	name, _, help := c.GetVersion()
+	if name != "" && fset.Lookup(name) == nil {
+		fset.Var(&_version, name, help)
 	}
 
 	// Parse the CLI arguments.
-	if err = f.fset.Parse(c.CliArgs()); err != nil {
+	if err = fset.Parse(expandCounterArgs(fset, c.CliArgs(), counters)); err != nil {
 		return
 	}
 
-	if _version != nil && *_version {
-		fmt.Println(version)
-		os.Exit(0)
+	// Mark the given flag set as parsed for the libraries using it, such as glog.
+	if !f.fset.Parsed() {
+		f.fset.Parse(nil)
+	}
+
+	if _version.set {
+		return c.handleVersion(_version.format)
 	}
 
-	// Acquire the result.
-	c.SetArgs(f.fset.Args())
-	f.fset.Visit(func(fg *flag.Flag) {
-		c.Printf("[%s] Parsing flag '%s'", f.Name(), fg.Name)
-		gname := name2group[fg.Name]
-		optname := name2opt[fg.Name]
-		if gname != "" && optname != "" && fg.Name != name {
-			c.SetOptValue(0, gname, optname, fg.Value.String())
+	for {
+		// Acquire the result.
+		fset.Visit(func(fg *flag.Flag) {
+			c.Printf("[%s] Parsing flag '%s'", f.Name(), fg.Name)
+			gname := name2group[fg.Name]
+			optname := name2opt[fg.Name]
+			if err == nil && gname != "" && optname != "" && fg.Name != name {
+				if v, ok := fg.Value.(optValue); ok {
+					err = c.SetOptValue(0, gname, optname, v.optValue())
+				} else {
+					err = c.SetOptValue(0, gname, optname, fg.Value.String())
+				}
+			}
+		})
+		if err != nil {
+			return
+		}
+
+		// Dispatch the sub-command by the first positional argument,
+		// and parse the options of the command from the rest arguments.
+		args := fset.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		cmd := c.SelectCommand(args[0])
+		if cmd == nil {
+			break
 		}
-	})
 
+		fset = f.newFlagSet(c, fmt.Sprintf("%s %s", f.fset.Name(), cmd.FullName()))
+		name2group, name2opt, counters = f.register(c, fset)
+		if err = fset.Parse(expandCounterArgs(fset, args[1:], counters)); err != nil {
+			return
+		}
+	}
+
+	c.SetArgs(fset.Args())
 	return
 }
 
@@ -230,7 +364,8 @@ type iniParser struct {
 // the option, optName, before parsing the option.
 func NewSimpleIniParser(optName string) Parser {
 	return NewIniParser(100, optName, func(c *Config) error {
-		c.RegisterCliOpt("", Str(optName, "", "The path of the INI config file."))
+		opt := Str(optName, "", "The path of the INI config file.")
+		c.RegisterCliOpt("", opt.(FileHintSetter).SetFileHint())
 		return nil
 	})
 }
@@ -262,6 +397,14 @@ func (p iniParser) Name() string {
 	return "ini"
 }
 
+func (p iniParser) FileFormat() string {
+	return "ini"
+}
+
+func (p iniParser) FileOpt() string {
+	return p.opt
+}
+
 func (p iniParser) Priority() int {
 	return p.prio
 }
@@ -278,18 +421,46 @@ func (p iniParser) Post(c *Config) error {
 }
 
 func (p iniParser) Parse(c *Config) error {
-	// Read the content of the config file.
 	filename := c.StringD(p.opt, "")
 	if filename == "" {
 		return nil
 	}
-	data, err := ioutil.ReadFile(filename)
+
+	// Parse the config file and its profile files.
+	var entries []fileEntry
+	for _, pf := range c.profileFiles(filename) {
+		es, err := p.parseFile(c, pf.file, pf.profile)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, es...)
+	}
+
+	for _, e := range c.sortProfileEntries(entries) {
+		value, err := c.expandFileEntry(e)
+		if err != nil {
+			return err
+		}
+		origin := Origin{File: e.file, Line: e.line}
+		if err = c.SetOptValueWithOrigin(p.prio, e.group, e.key, value, origin); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p iniParser) parseFile(c *Config, filename, profile string) ([]fileEntry, error) {
+	// Read the content of the config file.
+	data, err := c.ReadFile(filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Parse the config file.
+	var entries []fileEntry
 	gname := c.GetDefaultGroupName()
+	gprofile := profile
 	lines := strings.Split(string(data), "\n")
 	for index, maxIndex := 0, len(lines); index < maxIndex; {
 		line := strings.TrimSpace(lines[index])
@@ -308,27 +479,39 @@ func (p iniParser) Parse(c *Config) error {
 			continue
 		}
 
-		// Start a new group
+		// Start a new group, which may be only for a profile,
+		// such as "[group@profile]".
 		if line[0] == '[' && line[len(line)-1] == ']' {
 			gname = strings.TrimSpace(line[1 : len(line)-1])
+			gprofile = profile
+			if n := strings.LastIndex(gname, ProfileSeparator); n > -1 {
+				gprofile = strings.TrimSpace(gname[n+len(ProfileSeparator):])
+				if gname = strings.TrimSpace(gname[:n]); gname == "" {
+					gname = c.GetDefaultGroupName()
+				}
+				if gprofile == "" {
+					return nil, fmt.Errorf("the profile of the group '%s' is empty", gname)
+				}
+			}
 			if gname == "" {
-				return fmt.Errorf("the group is empty")
+				return nil, fmt.Errorf("the group is empty")
 			}
 			continue
 		}
 
 		n := strings.Index(line, p.sep)
 		if n == -1 {
-			return fmt.Errorf("the %dth line misses the separator '%s'", index, p.sep)
+			return nil, fmt.Errorf("the %dth line misses the separator '%s'", index, p.sep)
 		}
 
 		key := strings.TrimSpace(line[0:n])
 		for _, r := range key {
 			if r != '_' && r != '-' && !unicode.IsNumber(r) && !unicode.IsLetter(r) {
-				return fmt.Errorf("invalid identifier key '%s'", key)
+				return nil, fmt.Errorf("invalid identifier key '%s'", key)
 			}
 		}
 		value := strings.TrimSpace(line[n+len(p.sep) : len(line)])
+		lineno := index
 
 		// The continuation line
 		if value != "" && value[len(value)-1] == '\\' {
@@ -345,29 +528,162 @@ func (p iniParser) Parse(c *Config) error {
 			value = strings.TrimSpace(strings.Join(vs, "\n"))
 		}
 
-		if err = c.SetOptValue(p.prio, gname, key, value); err != nil {
-			return err
+		if gprofile != profile && !c.IsProfileActive(gprofile) {
+			c.debug("[%s] Ignore the option '%s' of the inactive profile '%s'",
+				p.Name(), key, gprofile)
+			continue
 		}
+
+		entries = append(entries, fileEntry{
+			group:   gname,
+			key:     key,
+			value:   value,
+			file:    filename,
+			line:    lineno,
+			profile: gprofile,
+		})
 	}
 
-	return nil
+	return entries, nil
+}
+
+// EnvVarNamer is an optional interface of Parser, which reports the names of
+// the environment variables of the option read by the parser, such as
+// the usage to display them.
+type EnvVarNamer interface {
+	EnvVarNames(c *Config, group *OptGroup, opt Opt) []string
 }
 
 type envVarParser struct {
 	prefix string
+	sep    string
+	isep   string
+	ksep   string
+	prio   int
+
+	transform func(string) string
+	groups    []string
+	excludes  []string
+	unmatched func([]string) error
 }
 
-// NewEnvVarParser returns a new environment variable parser.
+// EnvVarParserOption is the option of the environment variable parser.
+type EnvVarParserOption struct {
+	// Priority is the priority of the parser, which may be 0.
+	// The default is 10 if nil.
+	Priority *int
+
+	// Separator is the separator among the prefix, the group name and
+	// the option name, which the group separator is also converted to.
+	// The default is "_".
+	Separator string
+
+	// Transform transforms the generated name of the environment variable,
+	// such as "app_db_url". The default is strings.ToUpper.
+	//
+	// Notice: it is not applied to the explicit names of EnvVarOpt.
+	Transform func(name string) string
+
+	// Groups is the allowlist of the full names of the groups, the options
+	// of which and their sub-groups are parsed. The default is all the groups.
+	//
+	// For the default group, use the name of the default group, such as
+	// "DEFAULT".
+	Groups []string
+
+	// ExcludeGroups is the denylist of the full names of the groups, which
+	// takes precedence over Groups.
+	ExcludeGroups []string
+
+	// Unmatched is called with the sorted names of the environment variables
+	// that have the prefix but match no option, so the typo, such as
+	// "APP_DATABSE_URL", can be caught at startup. The options of the groups
+	// which are not allowed are not matched. If it returns an error,
+	// the parser fails with it.
+	//
+	// It is only called when the prefix is not empty.
+	Unmatched func(names []string) error
+
+	// IndexSep is the separator between the name and the index of the
+	// environment variable for the slice option, such as "APP_HOSTS_0",
+	// "APP_HOSTS_1", etc. The default is "_".
+	IndexSep string
+
+	// KeySep is the separator between the name and the key of the environment
+	// variable for the map option, such as "APP_LABELS__team". The default
+	// is "__".
+	KeySep string
+}
+
+// NewEnvVarParser returns a new environment variable parser with
+// the priority 10.
 //
 // For the environment variable name, it's the format "PREFIX_GROUP_OPTION".
 // If the prefix is empty, it's "GROUP_OPTION". For the default group, it's
 // "PREFIX_OPTION". When the prefix is empty and the group is the default,
 // it's "OPTION". "GROUP" is the group name, and "OPTION" is the option name.
 //
+// If the option has implemented the interface EnvVarOpt, its explicit names
+// take precedence over the generated name above.
+//
+// The slice option may also be given by the indexed environment variables,
+// such as "APP_HOSTS_0", "APP_HOSTS_1", etc, which are sorted by the index.
+// And the map option may be given by the environment variables with the key,
+// such as "APP_LABELS__team". If having none of them, it falls back to the
+// comma-separated form, such as "APP_HOSTS=host1,host2" and
+// "APP_LABELS=team=x,env=prod". See NewEnvVarParserWithOption.
+//
+// For the Docker convention, if the environment variable with the suffix
+// EnvVarFileSuffix, such as "DB_PASSWORD_FILE", is set, the content of the file
+// which it points to, trimmed the whitespaces, is the value of the option.
+// It is an error if both "DB_PASSWORD" and "DB_PASSWORD_FILE" are set.
+// The value read from the file is regarded as the secret, which is never
+// output into the debug log.
+//
 // Notice: the prefix, the group name and the option name will be converted to
 // the upper, and the group separator will be converted to "_".
 func NewEnvVarParser(prefix string) Parser {
-	return envVarParser{prefix: prefix}
+	return NewEnvVarParserWithOption(prefix, EnvVarParserOption{})
+}
+
+// EnvVarFileSuffix is the suffix of the environment variable whose value is
+// the path of the file that contains the option value, such as
+// "DB_PASSWORD_FILE=/run/secrets/db" for "DB_PASSWORD".
+var EnvVarFileSuffix = "_FILE"
+
+// NewEnvVarParserWithOption is the same as NewEnvVarParser, but customizes
+// the parser by the option.
+func NewEnvVarParserWithOption(prefix string, option EnvVarParserOption) Parser {
+	priority := 10
+	if option.Priority != nil {
+		if priority = *option.Priority; priority < 0 {
+			panic(fmt.Errorf("the priority must not be the negative"))
+		}
+	}
+	if option.Separator == "" {
+		option.Separator = "_"
+	}
+	if option.Transform == nil {
+		option.Transform = strings.ToUpper
+	}
+	if option.IndexSep == "" {
+		option.IndexSep = "_"
+	}
+	if option.KeySep == "" {
+		option.KeySep = "__"
+	}
+
+	return envVarParser{
+		prefix:    prefix,
+		sep:       option.Separator,
+		isep:      option.IndexSep,
+		ksep:      option.KeySep,
+		prio:      priority,
+		transform: option.Transform,
+		groups:    option.Groups,
+		excludes:  option.ExcludeGroups,
+		unmatched: option.Unmatched,
+	}
 }
 
 func (e envVarParser) Name() string {
@@ -375,7 +691,7 @@ func (e envVarParser) Name() string {
 }
 
 func (e envVarParser) Priority() int {
-	return 10
+	return e.prio
 }
 
 func (e envVarParser) Pre(c *Config) error {
@@ -386,38 +702,243 @@ func (e envVarParser) Post(c *Config) error {
 	return nil
 }
 
-func (e envVarParser) Parse(c *Config) (err error) {
-	// Initialize the prefix
+// isGroupIn reports whether the group or its parent group is in groups.
+func isGroupIn(c *Config, group string, groups []string) bool {
+	for _, g := range groups {
+		if g == group || strings.HasPrefix(group, g+c.GetGroupSeparator()) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowGroup reports whether the options of the group should be parsed.
+func (e envVarParser) allowGroup(c *Config, group *OptGroup) bool {
+	gname := group.FullName()
+	if len(e.groups) > 0 && !isGroupIn(c, gname, e.groups) {
+		return false
+	}
+	return !isGroupIn(c, gname, e.excludes)
+}
+
+// envVarName returns the generated name of the environment variable
+// of the option in the group.
+func (e envVarParser) envVarName(c *Config, group *OptGroup, opt Opt) string {
 	prefix := e.prefix
 	if prefix != "" {
-		prefix += "_"
+		prefix += e.sep
 	}
 
-	// Convert the option to the variable name
-	env2opts := make(map[string][]string, len(c.Groups())*8)
-	for _, group := range c.Groups() {
-		gname := ""
-		if group.Name() != c.GetDefaultGroupName() {
-			gname = strings.Replace(group.FullName(), c.GetGroupSeparator(), "_", -1) + "_"
+	gname := ""
+	if group.Name() != c.GetDefaultGroupName() {
+		gname = strings.Replace(group.FullName(), c.GetGroupSeparator(), e.sep, -1) + e.sep
+	}
+
+	return e.transform(fmt.Sprintf("%s%s%s", prefix, gname, opt.Name()))
+}
+
+// EnvVarNames implements the interface EnvVarNamer, which returns the names
+// of the environment variables of the option in the group by the precedence.
+func (e envVarParser) EnvVarNames(c *Config, group *OptGroup, opt Opt) []string {
+	if !e.allowGroup(c, group) {
+		return nil
+	}
+	return e.envVarNames(c, group, opt)
+}
+
+func (e envVarParser) envVarNames(c *Config, group *OptGroup, opt Opt) []string {
+	envs := GetEnvVars(opt)
+	names := make([]string, 0, len(envs)+1)
+	names = append(names, envs...)
+	return append(names, e.envVarName(c, group, opt))
+}
+
+// lookup looks up the value of the option from the environment variable
+// named name.
+func (e envVarParser) lookup(envs map[string]string, name string, opt Opt) (interface{}, bool) {
+	switch reflect.ValueOf(opt.Zero()).Kind() {
+	case reflect.Slice:
+		if vs := e.lookupIndexed(envs, name); len(vs) > 0 {
+			return vs, true
+		}
+	case reflect.Map:
+		if vs := e.lookupKeyed(envs, name); len(vs) > 0 {
+			return vs, true
+		}
+	}
+
+	value, ok := envs[name]
+	return value, ok
+}
+
+// lookupIndexed returns the values of the environment variables,
+// "NAME_0", "NAME_1", etc, sorted by the index.
+func (e envVarParser) lookupIndexed(envs map[string]string, name string) []string {
+	type indexValue struct {
+		index int
+		value string
+	}
+
+	var ivs []indexValue
+	prefix := name + e.isep
+	for key, value := range envs {
+		if !strings.HasPrefix(key, prefix) {
+			continue
 		}
+
+		index, err := strconv.ParseUint(key[len(prefix):], 10, 31)
+		if err == nil {
+			ivs = append(ivs, indexValue{index: int(index), value: value})
+		}
+	}
+
+	sort.Slice(ivs, func(i, j int) bool { return ivs[i].index < ivs[j].index })
+	values := make([]string, len(ivs))
+	for i, iv := range ivs {
+		values[i] = iv.value
+	}
+	return values
+}
+
+// lookupKeyed returns the key-value pairs of the environment variables,
+// "NAME__KEY".
+func (e envVarParser) lookupKeyed(envs map[string]string, name string) map[string]string {
+	var values map[string]string
+	prefix := name + e.ksep
+	for key, value := range envs {
+		if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+			if values == nil {
+				values = make(map[string]string, 4)
+			}
+			values[key[len(prefix):]] = value
+		}
+	}
+	return values
+}
+
+// isMatched reports whether the environment variable named name matches
+// an option, the names of whose environment variables are in names.
+func (e envVarParser) isMatched(name string, names map[string]Opt) bool {
+	if _, ok := names[name]; ok {
+		return true
+	} else if _, ok = names[strings.TrimSuffix(name, EnvVarFileSuffix)]; ok {
+		return true
+	}
+
+	// The indexed environment variable for the slice option.
+	if n := strings.LastIndex(name, e.isep); n > 0 {
+		if opt, ok := names[name[:n]]; ok {
+			_, err := strconv.ParseUint(name[n+len(e.isep):], 10, 31)
+			if err == nil && reflect.ValueOf(opt.Zero()).Kind() == reflect.Slice {
+				return true
+			}
+		}
+	}
+
+	// The environment variable with the key for the map option.
+	for n := strings.Index(name, e.ksep); n > 0; {
+		if opt, ok := names[name[:n]]; ok && reflect.ValueOf(opt.Zero()).Kind() == reflect.Map {
+			return true
+		}
+
+		m := strings.Index(name[n+len(e.ksep):], e.ksep)
+		if m == -1 {
+			break
+		}
+		n += len(e.ksep) + m
+	}
+
+	return false
+}
+
+func (e envVarParser) Parse(c *Config) (err error) {
+	// Get all the environment variables.
+	envs := make(map[string]string, 32)
+	for _, env := range os.Environ() {
+		if items := strings.SplitN(env, "=", 2); len(items) == 2 {
+			envs[items[0]] = items[1]
+		}
+	}
+
+	// Collect the names of the environment variables of all the options,
+	// so that "NAME_FILE" won't be regarded as the secret file variable
+	// when it's the name of another option.
+	allGroups := c.Groups()
+	groups := make([]*OptGroup, 0, len(allGroups))
+	names := make(map[string]Opt, len(allGroups)*8)
+	for _, group := range allGroups {
+		if !e.allowGroup(c, group) {
+			c.debug("[%s] Ignore the group '%s'", e.Name(), group.FullName())
+			continue
+		}
+
+		groups = append(groups, group)
 		for _, opt := range group.AllOpts() {
-			e := fmt.Sprintf("%s%s%s", prefix, gname, opt.Name())
-			env2opts[strings.ToUpper(e)] = []string{group.Name(), opt.Name()}
+			for _, name := range e.envVarNames(c, group, opt) {
+				names[name] = opt
+			}
 		}
 	}
 
 	// Get the option value from the environment variable.
-	envs := os.Environ()
-	for _, env := range envs {
-		c.Printf("[%s] Parsing Env '%s'", e.Name(), env)
-		items := strings.SplitN(env, "=", 2)
-		if len(items) == 2 {
-			if info, ok := env2opts[items[0]]; ok {
-				if err = c.SetOptValue(10, info[0], info[1], items[1]); err != nil {
+	for _, group := range groups {
+		for _, opt := range group.AllOpts() {
+			for _, name := range e.envVarNames(c, group, opt) {
+				value, ok := e.lookup(envs, name, opt)
+
+				filevar := name + EnvVarFileSuffix
+				filename, isFile := envs[filevar]
+				if _, isOpt := names[filevar]; isFile && !isOpt {
+					if ok {
+						return fmt.Errorf("both the environment variables '%s' and '%s' are set",
+							name, filevar)
+					}
+
+					c.Printf("[%s] Parsing Env '%s'", e.Name(), filevar)
+					data, err := ioutil.ReadFile(filename)
+					if err != nil {
+						return fmt.Errorf("failed to read the file of the environment variable"+
+							" '%s': %s", filevar, err)
+					}
+
+					// Not return the original error, which may contain the secret.
+					secret := strings.TrimSpace(string(data))
+					layer := c.newLayer(e.prio, secret, Origin{File: filename, EnvVar: filevar})
+					err = c.setOptValue(layer, group.Name(), opt.Name(), true)
+					if err != nil {
+						return fmt.Errorf("invalid value in the file of the environment variable '%s'"+
+							" for the option '%s' in the group '%s'", filevar, opt.Name(), group.Name())
+					}
+					break
+				} else if !ok {
+					continue
+				}
+
+				c.Printf("[%s] Parsing Env '%s'", e.Name(), name)
+				origin := Origin{EnvVar: name}
+				if err = c.SetOptValueWithOrigin(e.prio, group.Name(), opt.Name(), value, origin); err != nil {
 					return err
 				}
+				break
+			}
+		}
+	}
+
+	// Report the environment variables with the prefix that match no option.
+	if e.prefix != "" && e.unmatched != nil {
+		var unmatched []string
+		prefix := e.transform(e.prefix + e.sep)
+		for name := range envs {
+			if strings.HasPrefix(name, prefix) && !e.isMatched(name, names) {
+				unmatched = append(unmatched, name)
 			}
 		}
+
+		if len(unmatched) > 0 {
+			sort.Strings(unmatched)
+			c.debug("[%s] Unmatched environment variables: %v", e.Name(), unmatched)
+			return e.unmatched(unmatched)
+		}
 	}
 
 	return nil
@@ -434,7 +955,8 @@ type propertyParser struct {
 // which registers the option, optName, before parsing the option.
 func NewSimplePropertyParser(optName string) Parser {
 	return NewPropertyParser(100, optName, func(c *Config) error {
-		c.RegisterCliOpt("", Str(optName, "", "The path of the property config file."))
+		opt := Str(optName, "", "The path of the property config file.")
+		c.RegisterCliOpt("", opt.(FileHintSetter).SetFileHint())
 		return nil
 	})
 }
@@ -466,6 +988,14 @@ func (p propertyParser) Name() string {
 	return "property"
 }
 
+func (p propertyParser) FileFormat() string {
+	return "property"
+}
+
+func (p propertyParser) FileOpt() string {
+	return p.opt
+}
+
 func (p propertyParser) Priority() int {
 	return p.prio
 }
@@ -482,17 +1012,44 @@ func (p propertyParser) Post(c *Config) error {
 }
 
 func (p propertyParser) Parse(c *Config) error {
-	// Read the content of the config file.
 	filename := c.StringD(p.opt, "")
 	if filename == "" {
 		return nil
 	}
-	data, err := ioutil.ReadFile(filename)
+
+	// Parse the config file and its profile files.
+	var entries []fileEntry
+	for _, pf := range c.profileFiles(filename) {
+		es, err := p.parseFile(c, pf.file, pf.profile)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, es...)
+	}
+
+	for _, e := range entries {
+		value, err := c.expandFileEntry(e)
+		if err != nil {
+			return err
+		}
+		origin := Origin{File: e.file, Line: e.line}
+		if err = c.SetOptValueWithOrigin(p.prio, e.group, e.key, value, origin); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p propertyParser) parseFile(c *Config, filename, profile string) ([]fileEntry, error) {
+	// Read the content of the config file.
+	data, err := c.ReadFile(filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Parse the config file.
+	var entries []fileEntry
 	lines := strings.Split(string(data), "\n")
 	for index, maxIndex := 0, len(lines); index < maxIndex; {
 		line := strings.TrimSpace(lines[index])
@@ -513,31 +1070,28 @@ func (p propertyParser) Parse(c *Config) error {
 
 		ss := strings.SplitN(line, p.sep, 2)
 		if len(ss) != 2 {
-			return fmt.Errorf("the %dth line misses the separator '%s'", index, p.sep)
+			return nil, fmt.Errorf("the %dth line misses the separator '%s'", index, p.sep)
 		}
 
 		key := strings.TrimSpace(ss[0])
 		value := strings.TrimSpace(ss[1])
+		lineno := index
 		if value != "" {
 			for index < maxIndex && value[len(value)-1] == '\\' {
 				value = strings.TrimRight(value, "\\") + strings.TrimSpace(lines[index])
 				index++
-				c.Printf("[%s] Parsing %dth line: '%s'", p.Name(), index, lines[index])
+				c.Printf("[%s] Parsing %dth line: '%s'", p.Name(), index, lines[index-1])
 			}
 		}
 
+		entry := fileEntry{key: key, value: value, file: filename, line: lineno, profile: profile}
 		ss = strings.Split(key, c.GetGroupSeparator())
-		switch _len := len(ss) - 1; _len {
-		case 0:
-			err = c.SetOptValue(p.prio, "", key, value)
-		default:
-			err = c.SetOptValue(p.prio, strings.Join(ss[:_len], c.GetGroupSeparator()), ss[_len], value)
-		}
-
-		if err != nil {
-			return err
+		if _len := len(ss) - 1; _len > 0 {
+			entry.group = strings.Join(ss[:_len], c.GetGroupSeparator())
+			entry.key = ss[_len]
 		}
+		entries = append(entries, entry)
 	}
 
-	return nil
+	return entries, nil
 }
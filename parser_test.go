@@ -0,0 +1,287 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvVarParserExplicitNames(t *testing.T) {
+	os.Setenv("EXPLICIT_URL", "generated")
+	os.Setenv("DATABASE_URL", "explicit")
+	os.Setenv("EXPLICIT_PORT", "8080")
+	os.Setenv("PORT_ALIAS2", "9090")
+	defer func() {
+		for _, name := range []string{"EXPLICIT_URL", "DATABASE_URL", "EXPLICIT_PORT", "PORT_ALIAS2"} {
+			os.Unsetenv(name)
+		}
+	}()
+
+	type S struct {
+		Port int `env:"PORT_ALIAS1, PORT_ALIAS2"`
+	}
+
+	var s S
+	conf := NewConfig().AddParser(NewEnvVarParser("explicit"))
+	conf.RegisterOpt("", Str("url", "", "the url").(EnvVarSetter).SetEnvVars("DATABASE_URL"))
+	conf.RegisterStruct("", &s)
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.String("url"); v != "explicit" {
+		t.Errorf("expect '%s', but got '%s'", "explicit", v)
+	}
+	if s.Port != 9090 {
+		t.Errorf("expect %d, but got %d", 9090, s.Port)
+	}
+}
+
+func TestEnvVarParserIndexedAndKeyed(t *testing.T) {
+	envs := map[string]string{
+		"INDEXED_HOSTS_10":     "host3",
+		"INDEXED_HOSTS_2":      "host2,with,comma",
+		"INDEXED_HOSTS_0":      "host1",
+		"INDEXED_PORTS":        "80,443",
+		"INDEXED_LABELS__team": "x",
+		"INDEXED_LABELS__env":  "prod",
+		"INDEXED_TAGS":         "a=1,b=2",
+	}
+	for key, value := range envs {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	conf := NewConfig().AddParser(NewEnvVarParser("indexed"))
+	conf.RegisterOpts("", []Opt{
+		Strings("hosts", nil, ""),
+		Ints("ports", nil, ""),
+		StringMap("labels", nil, ""),
+		StringMap("tags", nil, ""),
+	})
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if hosts := conf.Strings("hosts"); !reflect.DeepEqual(hosts,
+		[]string{"host1", "host2,with,comma", "host3"}) {
+		t.Errorf("unexpected hosts %q", hosts)
+	}
+	if ports := conf.Ints("ports"); !reflect.DeepEqual(ports, []int{80, 443}) {
+		t.Errorf("unexpected ports %v", ports)
+	}
+	if labels := conf.StringMap("labels"); !reflect.DeepEqual(labels,
+		map[string]string{"team": "x", "env": "prod"}) {
+		t.Errorf("unexpected labels %v", labels)
+	}
+	if tags := conf.StringMap("tags"); !reflect.DeepEqual(tags,
+		map[string]string{"a": "1", "b": "2"}) {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestEnvVarParserFileSuffix(t *testing.T) {
+	file, err := ioutil.TempFile("", "go-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("  secret\n")
+	file.Close()
+
+	os.Setenv("SECRET_PASSWORD_FILE", file.Name())
+	defer os.Unsetenv("SECRET_PASSWORD_FILE")
+
+	// The option "config_file" is not regarded as the file of "config".
+	os.Setenv("SECRET_CONFIG_FILE", "/path/to/file")
+	defer os.Unsetenv("SECRET_CONFIG_FILE")
+
+	conf := NewConfig().AddParser(NewEnvVarParser("secret"))
+	conf.RegisterOpts("", []Opt{
+		Str("password", "", ""),
+		Str("config", "", ""),
+		Str("config_file", "", ""),
+	})
+	if err = conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.String("password"); v != "secret" {
+		t.Errorf("expect '%s', but got '%s'", "secret", v)
+	}
+	if layer, ok := conf.Group("").Source("password"); !ok || !layer.Secret {
+		t.Errorf("expect the secret layer, but got %+v", layer)
+	} else if explain := conf.Group("").Explain("password"); !strings.Contains(explain, ": ******, won") {
+		t.Errorf("the secret value is not masked:\n%s", explain)
+	}
+	if v := conf.String("config"); v != "" {
+		t.Errorf("expect '%s', but got '%s'", "", v)
+	}
+	if v := conf.String("config_file"); v != "/path/to/file" {
+		t.Errorf("expect '%s', but got '%s'", "/path/to/file", v)
+	}
+
+	// Both the forms are set.
+	os.Setenv("SECRET_PASSWORD", "password")
+	defer os.Unsetenv("SECRET_PASSWORD")
+	conf = NewConfig().AddParser(NewEnvVarParser("secret"))
+	conf.RegisterOpt("", Str("password", "", ""))
+	if err = conf.Parse(); err == nil {
+		t.Error("expect an error when both the forms are set")
+	}
+}
+
+func TestEnvVarParserWithOption(t *testing.T) {
+	envs := map[string]string{
+		"OPTION-DB-URL":      "url",
+		"OPTION-DB-USER":     "user",
+		"OPTION-DATABSE-URL": "typo",
+		"OPTION-LOG-LEVEL":   "debug",
+	}
+	for key, value := range envs {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	var unmatched []string
+	priority := 20
+	parser := NewEnvVarParserWithOption("option", EnvVarParserOption{
+		Priority:      &priority,
+		Separator:     "-",
+		ExcludeGroups: []string{"log"},
+		Unmatched: func(names []string) error {
+			unmatched = names
+			return nil
+		},
+	})
+	if parser.Priority() != 20 {
+		t.Errorf("expect the priority %d, but got %d", 20, parser.Priority())
+	}
+	priority = 0
+	if p := NewEnvVarParserWithOption("", EnvVarParserOption{Priority: &priority}); p.Priority() != 0 {
+		t.Errorf("expect the priority %d, but got %d", 0, p.Priority())
+	} else if p = NewEnvVarParserWithOption("", EnvVarParserOption{}); p.Priority() != 10 {
+		t.Errorf("expect the priority %d, but got %d", 10, p.Priority())
+	}
+
+	conf := NewConfig().AddParser(parser)
+	conf.RegisterOpts("db", []Opt{Str("url", "", ""), Str("user", "", "")})
+	conf.RegisterOpt("log", Str("level", "info", ""))
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.Group("db").String("url"); v != "url" {
+		t.Errorf("expect '%s', but got '%s'", "url", v)
+	}
+	if v := conf.Group("log").String("level"); v != "info" {
+		t.Errorf("expect '%s', but got '%s'", "info", v)
+	}
+	if !reflect.DeepEqual(unmatched, []string{"OPTION-DATABSE-URL", "OPTION-LOG-LEVEL"}) {
+		t.Errorf("unexpected unmatched environment variables %v", unmatched)
+	}
+}
+
+func TestFlagCliParserRepeatedAndCounter(t *testing.T) {
+	opts := func() []Opt {
+		return []Opt{
+			Strings("host", nil, ""),
+			Ints("port", nil, ""),
+			StringMap("label", nil, ""),
+			CountOpt("v", "verbose", ""),
+		}
+	}
+	args := []string{"-host", "a,b", "-host", "c", "-port", "80", "-port", "443",
+		"-label", "k1=v1", "-label", "k2=v2", "-vvv", "-verbose"}
+
+	conf := NewConfig().AddParser(NewFlagCliParser(nil, false))
+	conf.RegisterCliOpts("", opts())
+	if err := conf.Parse(args...); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.Strings("host"); !reflect.DeepEqual(v, []string{"a", "b", "c"}) {
+		t.Errorf("unexpected hosts %q", v)
+	}
+	if v := conf.Ints("port"); !reflect.DeepEqual(v, []int{80, 443}) {
+		t.Errorf("unexpected ports %v", v)
+	}
+	if v := conf.StringMap("label"); !reflect.DeepEqual(v, map[string]string{"k1": "v1", "k2": "v2"}) {
+		t.Errorf("unexpected labels %v", v)
+	}
+	if v := conf.Int("verbose"); v != 4 {
+		t.Errorf("expect %d, but got %d", 4, v)
+	}
+
+	conf = NewConfig().AddParser(NewFlagCliParserWithOption(nil, CliParserOption{}))
+	conf.RegisterCliOpts("", opts())
+	if err := conf.Parse(args...); err != nil {
+		t.Fatal(err)
+	}
+	if v := conf.Strings("host"); !reflect.DeepEqual(v, []string{"a,b", "c"}) {
+		t.Errorf("unexpected hosts %q", v)
+	}
+
+	// The value of the flag like the counter flags is not expanded.
+	conf = NewConfig().AddParser(NewFlagCliParser(nil, false))
+	conf.RegisterCliOpts("", []Opt{Str("name", "", ""), CountOpt("v", "verbose", "")})
+	if err := conf.Parse("-name", "-vv", "-v", "arg", "-vv"); err != nil {
+		t.Fatal(err)
+	} else if v := conf.String("name"); v != "-vv" {
+		t.Errorf("expect the name '%s', but got '%s'", "-vv", v)
+	} else if v := conf.Int("verbose"); v != 1 {
+		t.Errorf("expect %d, but got %d", 1, v)
+	} else if v := conf.Args(); !reflect.DeepEqual(v, []string{"arg", "-vv"}) {
+		t.Errorf("unexpected the arguments %q", v)
+	}
+}
+
+func TestFlagCliParserInvalidValue(t *testing.T) {
+	conf := NewConfig().AddParser(NewFlagCliParser(nil, true))
+	port := Int("port", 80, "").(ValidatorChainOpt).SetValidators(NewPortValidator())
+	conf.RegisterCliOpt("", port)
+	if err := conf.Parse("-port", "99999"); err == nil {
+		t.Error("expect an error for the invalid port")
+	}
+}
+
+func TestSliceValueCopy(t *testing.T) {
+	v := &sliceValue{split: true}
+	v.Set("a,b")
+
+	values := v.optValue().([]string)
+	values[0] = "x"
+	if v.values[0] != "a" {
+		t.Errorf("the internal slice is shared: %v", v.values)
+	}
+}
+
+func TestFlagCliParserNotChangeFlagSet(t *testing.T) {
+	var usage bool
+	fset := flag.NewFlagSet("test", flag.ContinueOnError)
+	fset.Usage = func() { usage = true }
+	other := fset.Bool("other", false, "the flag not managed by the config")
+
+	conf := NewConfig().AddParser(NewFlagCliParser(fset, false))
+	conf.RegisterCliOpt("", Int("port", 80, ""))
+	if err := conf.Parse("-other", "-port", "8080"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !*other {
+		t.Error("the flag of the flag set is not parsed")
+	} else if v := conf.Int("port"); v != 8080 {
+		t.Errorf("expect %d, but got %d", 8080, v)
+	} else if fset.Lookup("port") != nil {
+		t.Error("the option is registered into the given flag set")
+	} else if !fset.Parsed() {
+		t.Error("the given flag set is not marked as parsed")
+	}
+
+	if fset.Usage(); !usage {
+		t.Error("the usage of the given flag set is replaced")
+	}
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// pflagValue adapts optValue to pflag.Value for the option types
+// which pflag does not support, such as []uint64 and []time.Time.
+type pflagValue struct {
+	optValue
+	typ string
+}
+
+func (v pflagValue) Type() string { return v.typ }
+
+type pflagParser struct {
+	utoh  bool
+	split bool
+	fset  *pflag.FlagSet
+}
+
+// NewPflagCliParser returns a new CLI parser based on pflag.FlagSet of
+// github.com/spf13/pflag, which is used by github.com/spf13/cobra.
+//
+// If flagSet is nil, it will create a default pflag.FlagSet, which is equal to
+//
+//    pflag.NewFlagSet(filepath.Base(os.Args[0]), pflag.ContinueOnError)
+//
+// If underlineToHyphen is true, it will convert the underline to the hyphen.
+//
+// Every CLI option is registered as the typed pflag value, and its one
+// character short name is registered as the shorthand, such as "-p". The slice
+// and map options may be given repeatedly, the value of which is split by
+// the comma, and the int counter option is registered as pflag.Count.
+// See NewPflagCliParserWithOption.
+//
+// If the flag set has been parsed, such as the flags of the cobra command,
+// the parser only collects the changed flags and the rest arguments from it
+// without parsing the CLI arguments again. So the options should be registered
+// into the flag set before it's parsed by RegisterPflags. For example,
+//
+//    conf := config.NewConfig()
+//    conf.RegisterCliOpts("", opts)
+//    config.RegisterPflags(conf, cmd.Flags(), config.CliParserOption{})
+//    conf.AddParser(config.NewPflagCliParser(cmd.Flags(), false))
+//    cmd.RunE = func(cmd *cobra.Command, args []string) error {
+//        return conf.Parse()
+//    }
+//
+// Or, it parses the CLI arguments by a new flag set with the flags of flagSet,
+// and dispatches the sub-command by the first rest argument like
+// NewFlagCliParser, so flagSet is not changed except being marked as parsed.
+// In this case, the interspersed option is disabled if there are
+// the sub-commands. The help and the version options are handled like
+// NewFlagCliParser.
+func NewPflagCliParser(flagSet *pflag.FlagSet, underlineToHyphen bool) Parser {
+	return NewPflagCliParserWithOption(flagSet, CliParserOption{
+		UnderlineToHyphen: underlineToHyphen,
+		SplitComma:        true,
+	})
+}
+
+// NewPflagCliParserWithOption is the same as NewPflagCliParser, but configures
+// the parser by the option.
+//
+// If option.SplitComma is false, the []string option is registered as
+// pflag.StringArray instead of pflag.StringSlice.
+func NewPflagCliParserWithOption(flagSet *pflag.FlagSet, option CliParserOption) Parser {
+	if flagSet == nil {
+		flagSet = pflag.NewFlagSet(filepath.Base(os.Args[0]), pflag.ContinueOnError)
+	}
+
+	return pflagParser{
+		fset:  flagSet,
+		utoh:  option.UnderlineToHyphen,
+		split: option.SplitComma,
+	}
+}
+
+// RegisterPflags registers the CLI options of the global and the selected
+// command into the pflag.FlagSet, which is used to register the options into
+// the flags of the cobra command before it's parsed. See NewPflagCliParser.
+//
+// The flag that has been defined in the flag set is skipped, and nothing is
+// registered if the flag set has been parsed.
+func RegisterPflags(c *Config, fset *pflag.FlagSet, option CliParserOption) {
+	pflagParser{utoh: option.UnderlineToHyphen, split: option.SplitComma}.register(c, fset)
+}
+
+func (f pflagParser) Name() string {
+	return "pflag"
+}
+
+func (f pflagParser) Priority() int {
+	return 0
+}
+
+func (f pflagParser) Pre(c *Config) error {
+	return nil
+}
+
+func (f pflagParser) Post(c *Config) error {
+	return nil
+}
+
+func (f pflagParser) cliStyle() cliStyle {
+	return cliStyle{prefix: "--", short: true, utoh: f.utoh}
+}
+
+// register registers the CLI options of the global and the selected command
+// path into fset, and returns the mappings from the flag name to the group
+// and option.
+func (f pflagParser) register(c *Config, fset *pflag.FlagSet) (name2group map[string]string,
+	name2opt map[string]Opt) {
+	name2group = make(map[string]string, 8)
+	name2opt = make(map[string]Opt, 8)
+	for _, group := range c.Groups() {
+		gname := group.FullName()
+		for _, opt := range group.CliOpts() {
+			name := cliOptName(c, gname, opt.Name(), f.utoh)
+			name2group[name] = gname
+			name2opt[name] = opt
+			if fset.Lookup(name) != nil || fset.Parsed() {
+				continue
+			}
+
+			short := opt.Short()
+			if len(short) != 1 || fset.ShorthandLookup(short) != nil {
+				short = ""
+			}
+
+			help := opt.Help()
+			if envs := GetEnvVars(opt); len(envs) > 0 {
+				help = fmt.Sprintf("%s (env: %s)", help, strings.Join(envs, ", "))
+			}
+
+			f.registerOpt(fset, name, short, help, opt)
+		}
+	}
+	return
+}
+
+func (f pflagParser) registerOpt(fset *pflag.FlagSet, name, short, help string, opt Opt) {
+	if IsCounterOpt(opt) {
+		fset.CountP(name, short, help)
+		return
+	}
+
+	_default := opt.Default()
+	switch opt.Zero().(type) {
+	case bool:
+		v, _ := _default.(bool)
+		fset.BoolP(name, short, v, help)
+	case int, int8, int16, int32, int64:
+		var v int64
+		if _default != nil {
+			v, _ = ToInt64(_default)
+		}
+		fset.Int64P(name, short, v, help)
+	case uint, uint8, uint16, uint32, uint64:
+		var v uint64
+		if _default != nil {
+			v, _ = ToUint64(_default)
+		}
+		fset.Uint64P(name, short, v, help)
+	case float32, float64:
+		var v float64
+		if _default != nil {
+			v, _ = ToFloat64(_default)
+		}
+		fset.Float64P(name, short, v, help)
+	case time.Duration:
+		v, _ := _default.(time.Duration)
+		fset.DurationP(name, short, v, help)
+	case []string:
+		v, _ := _default.([]string)
+		if f.split {
+			fset.StringSliceP(name, short, v, help)
+		} else {
+			fset.StringArrayP(name, short, v, help)
+		}
+	case []int:
+		v, _ := _default.([]int)
+		fset.IntSliceP(name, short, v, help)
+	case []int64:
+		v, _ := _default.([]int64)
+		fset.Int64SliceP(name, short, v, help)
+	case []uint:
+		v, _ := _default.([]uint)
+		fset.UintSliceP(name, short, v, help)
+	case []float64:
+		v, _ := _default.([]float64)
+		fset.Float64SliceP(name, short, v, help)
+	case []time.Duration:
+		v, _ := _default.([]time.Duration)
+		fset.DurationSliceP(name, short, v, help)
+	case map[string]string:
+		v, _ := _default.(map[string]string)
+		fset.StringToStringP(name, short, v, help)
+	default:
+		if isMultiOpt(opt) {
+			value := pflagValue{optValue: &sliceValue{split: f.split}, typ: "strings"}
+			fset.VarP(value, name, short, help)
+			return
+		}
+
+		var v string
+		if _default != nil {
+			v = fmt.Sprintf("%v", _default)
+		}
+		fset.StringP(name, short, v, help)
+	}
+}
+
+// getPflagValue returns the typed value of the flag registered by registerOpt.
+func getPflagValue(fset *pflag.FlagSet, fg *pflag.Flag, opt Opt) (interface{}, error) {
+	if v, ok := fg.Value.(optValue); ok {
+		return v.optValue(), nil
+	} else if IsCounterOpt(opt) {
+		return fset.GetCount(fg.Name)
+	}
+
+	switch opt.Zero().(type) {
+	case bool:
+		return fset.GetBool(fg.Name)
+	case int, int8, int16, int32, int64:
+		return fset.GetInt64(fg.Name)
+	case uint, uint8, uint16, uint32, uint64:
+		return fset.GetUint64(fg.Name)
+	case float32, float64:
+		return fset.GetFloat64(fg.Name)
+	case time.Duration:
+		return fset.GetDuration(fg.Name)
+	case []string:
+		if fg.Value.Type() == "stringArray" {
+			return fset.GetStringArray(fg.Name)
+		}
+		return fset.GetStringSlice(fg.Name)
+	case []int:
+		return fset.GetIntSlice(fg.Name)
+	case []int64:
+		return fset.GetInt64Slice(fg.Name)
+	case []uint:
+		return fset.GetUintSlice(fg.Name)
+	case []float64:
+		return fset.GetFloat64Slice(fg.Name)
+	case []time.Duration:
+		return fset.GetDurationSlice(fg.Name)
+	case map[string]string:
+		return fset.GetStringToString(fg.Name)
+	default:
+		return fg.Value.String(), nil
+	}
+}
+
+// newFlagSet returns a new flag set to parse the CLI arguments, which returns
+// the error instead of exiting and calls the help handler for the help option.
+func (f pflagParser) newFlagSet(c *Config, name string) *pflag.FlagSet {
+	fset := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	fset.SetOutput(os.Stderr)
+	fset.SetNormalizeFunc(f.fset.GetNormalizeFunc())
+	fset.Usage = func() { c.handleHelp() }
+	if len(c.Commands()) > 0 {
+		fset.SetInterspersed(false)
+	}
+	return fset
+}
+
+// parse parses the arguments by fset.
+func (f pflagParser) parse(c *Config, fset *pflag.FlagSet, args []string) (err error) {
+	if err = fset.Parse(args); err == pflag.ErrHelp {
+		err = ErrHelp
+	}
+	return
+}
+
+func (f pflagParser) Parse(c *Config) (err error) {
+	fset := f.fset
+	if !fset.Parsed() {
+		// Parse the CLI arguments by a new flag set with the flags of
+		// the given one, so that it is not changed.
+		fset = f.newFlagSet(c, filepath.Base(os.Args[0]))
+		fset.AddFlagSet(f.fset)
+	}
+	name2group, name2opt := f.register(c, fset)
+
+	// Register the version option.
+	var _version versionValue
+	name, _, help := c.GetVersion()
+	if name != "" && fset.Lookup(name) == nil {
+		fset.VarPF(&_version, name, "", help).NoOptDefVal = "true"
+	}
+
+	if fset != f.fset {
+		if err = f.parse(c, fset, c.CliArgs()); err != nil {
+			return
+		}
+
+		// Mark the given flag set as parsed for the libraries using it.
+		f.fset.Parse(nil)
+		if _version.set {
+			return c.handleVersion(_version.format)
+		}
+	}
+
+	for {
+		// Acquire the result.
+		fset.Visit(func(fg *pflag.Flag) {
+			opt := name2opt[fg.Name]
+			if err != nil || opt == nil {
+				return
+			}
+
+			c.Printf("[%s] Parsing flag '%s'", f.Name(), fg.Name)
+			var value interface{}
+			if value, err = getPflagValue(fset, fg, opt); err == nil {
+				err = c.SetOptValue(0, name2group[fg.Name], opt.Name(), value)
+			}
+		})
+		if err != nil {
+			return
+		}
+
+		// Dispatch the sub-command by the first positional argument,
+		// and parse the options of the command from the rest arguments.
+		args := fset.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		cmd := c.SelectCommand(args[0])
+		if cmd == nil {
+			break
+		}
+
+		_fset := f.newFlagSet(c, cmd.FullName())
+		name2group, name2opt = f.register(c, _fset)
+		if err = f.parse(c, _fset, args[1:]); err != nil {
+			return
+		}
+		fset = _fset
+	}
+
+	c.SetArgs(fset.Args())
+	return
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func TestPflagCliParser(t *testing.T) {
+	conf := NewConfig().AddParser(NewPflagCliParser(nil, true))
+	conf.RegisterCliOpts("", []Opt{
+		IntOpt("p", "port", 80, ""),
+		DurationOpt("t", "timeout", time.Second, ""),
+		Strings("host", nil, ""),
+		Uint64s("id", nil, ""),
+		StringMap("label", nil, ""),
+		CountOpt("v", "verbose", ""),
+	})
+	conf.RegisterCliOpt("log", Str("file_path", "", ""))
+
+	args := []string{"-p", "8080", "--timeout=3s", "--host", "a,b", "--host", "c",
+		"--id", "1", "--id", "2,3", "--label", "k1=v1", "-vvv", "--log.file-path", "/log", "arg"}
+	if err := conf.Parse(args...); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.Int("port"); v != 8080 {
+		t.Errorf("expect %d, but got %d", 8080, v)
+	}
+	if v := conf.Duration("timeout"); v != 3*time.Second {
+		t.Errorf("expect %s, but got %s", 3*time.Second, v)
+	}
+	if v := conf.Strings("host"); !reflect.DeepEqual(v, []string{"a", "b", "c"}) {
+		t.Errorf("unexpected hosts %q", v)
+	}
+	if v := conf.Uint64s("id"); !reflect.DeepEqual(v, []uint64{1, 2, 3}) {
+		t.Errorf("unexpected ids %v", v)
+	}
+	if v := conf.StringMap("label"); !reflect.DeepEqual(v, map[string]string{"k1": "v1"}) {
+		t.Errorf("unexpected labels %v", v)
+	}
+	if v := conf.Int("verbose"); v != 3 {
+		t.Errorf("expect %d, but got %d", 3, v)
+	}
+	if v := conf.Group("log").String("file_path"); v != "/log" {
+		t.Errorf("expect '%s', but got '%s'", "/log", v)
+	}
+	if args := conf.Args(); !reflect.DeepEqual(args, []string{"arg"}) {
+		t.Errorf("unexpected rest arguments %q", args)
+	}
+}
+
+func TestPflagCliParserParsedFlagSet(t *testing.T) {
+	fset := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fset.Bool("other", false, "the flag not managed by the config")
+
+	conf := NewConfig().AddParser(NewPflagCliParser(fset, false))
+	conf.RegisterCliOpt("", IntOpt("p", "port", 80, ""))
+	RegisterPflags(conf, fset, CliParserOption{})
+	if fset.Lookup("port") == nil || fset.ShorthandLookup("p") == nil {
+		t.Fatal("the option is not registered into the flag set")
+	}
+
+	// Parse the flag set by others, such as cobra.
+	if err := fset.Parse([]string{"--other", "-p", "8080", "arg"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conf.Parse("--port", "9090"); err != nil {
+		t.Fatal(err)
+	}
+	if v := conf.Int("port"); v != 8080 {
+		t.Errorf("expect %d, but got %d", 8080, v)
+	}
+	if args := conf.Args(); !reflect.DeepEqual(args, []string{"arg"}) {
+		t.Errorf("unexpected rest arguments %q", args)
+	}
+}
+
+func TestPflagCliParserNotChangeFlagSet(t *testing.T) {
+	var usage bool
+	fset := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fset.Usage = func() { usage = true }
+	other := fset.Bool("other", false, "the flag not managed by the config")
+
+	conf := NewConfig().AddParser(NewPflagCliParser(fset, false))
+	conf.RegisterCliOpt("", IntOpt("p", "port", 80, ""))
+	conf.NewCommand("serve", "")
+	if err := conf.Parse("--other", "-p", "8080"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !*other {
+		t.Error("the flag of the flag set is not parsed")
+	} else if v := conf.Int("port"); v != 8080 {
+		t.Errorf("expect %d, but got %d", 8080, v)
+	} else if fset.Lookup("port") != nil {
+		t.Error("the option is registered into the given flag set")
+	} else if !fset.Parsed() {
+		t.Error("the given flag set is not marked as parsed")
+	}
+
+	if fset.Usage(); !usage {
+		t.Error("the usage of the given flag set is replaced")
+	}
+	if err := fset.Parse([]string{"a", "--other"}); err != nil || len(fset.Args()) != 1 {
+		t.Errorf("the interspersed option of the given flag set is changed: %v", err)
+	}
+}
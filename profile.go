@@ -0,0 +1,145 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProfileSeparator is the separator between the section name and the profile
+// in the ini file, such as "[section@prod]".
+var ProfileSeparator = "@"
+
+// SetProfileOpt registers the CLI option named name, such as "profile",
+// into the default group, which is used to give the active profiles
+// separated by the comma, such as "--profile prod,local".
+//
+// If the option has a value, it will override the profiles set by SetProfiles.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) SetProfileOpt(name string) *Config {
+	c.panicIsParsed(true)
+	c.RegisterCliOpt("", Strings(name, nil,
+		"The active profiles separated by the comma, the latter of which has the higher priority."))
+	c.profileOpt = name
+	return c
+}
+
+// SetProfiles sets the active profiles in order, the latter of which has
+// the higher priority.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) SetProfiles(profiles ...string) *Config {
+	c.panicIsParsed(true)
+	c.profiles = profiles
+	return c
+}
+
+// Profiles returns the active profiles in order.
+//
+// Notice: the file parsers should call it only when parsing, because the
+// profiles may be given by the CLI or environment variable parser.
+func (c *Config) Profiles() []string {
+	if c.profileOpt != "" {
+		if ps, err := c.StringsE(c.profileOpt); err == nil && len(ps) > 0 {
+			return ps
+		}
+	}
+	return c.profiles
+}
+
+// IsProfileActive reports whether the profile is active.
+func (c *Config) IsProfileActive(profile string) bool {
+	return profileIndex(c.Profiles(), profile) > 0
+}
+
+// profileIndex returns the layer index of the profile in profiles, which is 0
+// for the empty profile, that's the base layer, and -1 for the inactive one.
+func profileIndex(profiles []string, profile string) int {
+	if profile == "" {
+		return 0
+	}
+	for i, p := range profiles {
+		if p == profile {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+type profileFile struct {
+	file    string
+	profile string
+}
+
+func (c *Config) profileFiles(filename string) []profileFile {
+	files := []profileFile{{file: filename}}
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	for _, profile := range c.Profiles() {
+		file := base + "." + profile + ext
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, profileFile{file: file, profile: profile})
+		} else {
+			c.debug("Ignore the profile file '%s': %s", file, err)
+		}
+	}
+	return files
+}
+
+// ProfileFiles returns the config file, filename, and its profile files
+// which exist, in order.
+//
+// The name of the profile file is inserted the profile before the extension.
+// For example, for the active profiles "prod" and "local", the files of
+// "app.ini" are "app.ini", "app.prod.ini" and "app.local.ini".
+//
+// The file parsers should parse these files in turn, and the latter file
+// should override the former.
+func (c *Config) ProfileFiles(filename string) []string {
+	pfs := c.profileFiles(filename)
+	files := make([]string, len(pfs))
+	for i, pf := range pfs {
+		files[i] = pf.file
+	}
+	return files
+}
+
+// fileEntry is an option value parsed from the config file.
+type fileEntry struct {
+	group   string
+	key     string
+	value   string
+	file    string
+	line    int
+	profile string
+}
+
+// sortProfileEntries sorts the entries by the layers of their profiles,
+// so that the value of the latter profile will override the former when
+// setting them in turn. The entries in the same layer keep the parsed order.
+func (c *Config) sortProfileEntries(entries []fileEntry) []fileEntry {
+	profiles := c.Profiles()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return profileIndex(profiles, entries[i].profile) <
+			profileIndex(profiles, entries[j].profile)
+	})
+	return entries
+}
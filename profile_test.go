@@ -0,0 +1,62 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"app.ini": `
+[DEFAULT]
+opt1 = base
+opt2 = base
+opt3 = base
+
+[DEFAULT@local]
+opt1 = local-section
+
+[@prod]
+opt1 = prod-section
+opt2 = prod-section
+`,
+		"app.prod.ini": `
+opt3 = prod-file
+`,
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := NewConfig().AddParser(NewFlagCliParser(nil, true), NewSimpleIniParser("config-file"))
+	conf.SetProfileOpt("profile")
+	conf.RegisterOpts("", []Opt{Str("opt1", "", ""), Str("opt2", "", ""), Str("opt3", "", "")})
+
+	args := []string{"--config-file", filepath.Join(dir, "app.ini"), "--profile", "prod,local"}
+	if err = conf.Parse(args...); err != nil {
+		t.Fatal(err)
+	}
+
+	if ps := conf.Profiles(); len(ps) != 2 || ps[0] != "prod" || ps[1] != "local" {
+		t.Errorf("unexpected profiles %v", ps)
+	}
+	if v := conf.String("opt1"); v != "local-section" {
+		t.Errorf("opt1: expect '%s', got '%s'", "local-section", v)
+	}
+	if v := conf.String("opt2"); v != "prod-section" {
+		t.Errorf("opt2: expect '%s', got '%s'", "prod-section", v)
+	}
+	if v := conf.String("opt3"); v != "prod-file" {
+		t.Errorf("opt3: expect '%s', got '%s'", "prod-file", v)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// EnablePrompt enables to prompt for the value of the required option which
+// has no value after parsing, instead of returning an error. The prompt is
+// written into out, and the value is read from in line by line.
+//
+// If in is nil, it's os.Stdin. If out is nil, it's os.Stderr.
+//
+// If in is an *os.File, such as os.Stdin, it only prompts when in is attached
+// to a terminal, and the value of the sensitive option is read without the echo.
+// See SensitiveOpt.
+//
+// The prompt shows the help, the default value and the allowed choices of
+// the option. If the input is empty, the default value is used if it exists.
+// The value is validated by the validators of the option, and it prompts again
+// if it's invalid. The prompted value has the priority 0 like the CLI parser.
+func (c *Config) EnablePrompt(in io.Reader, out io.Writer) *Config {
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stderr
+	}
+
+	c.promptIn = in
+	c.promptOut = out
+	c.promptReader = bufio.NewReader(in)
+	return c
+}
+
+// canPrompt reports whether it can prompt for the value of the option.
+func (c *Config) canPrompt() bool {
+	if c.promptIn == nil {
+		return false
+	} else if f, ok := c.promptIn.(*os.File); ok {
+		return term.IsTerminal(int(f.Fd()))
+	}
+	return true
+}
+
+// readPrompt reads a line from the prompt input without the trailing newline.
+// If hidden is true and the input is a terminal, the line is not echoed.
+func (c *Config) readPrompt(hidden bool) (string, error) {
+	if f, ok := c.promptIn.(*os.File); ok && hidden && term.IsTerminal(int(f.Fd())) {
+		line, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(c.promptOut)
+		return string(line), err
+	}
+
+	line, err := c.promptReader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// promptOptValue prompts for the value of the option until it's valid,
+// and sets it.
+func (g *OptGroup) promptOptValue(name string, opt Opt) (err error) {
+	c := g.conf
+	sensitive := IsSensitiveOpt(opt)
+	flag := cliOptName(c, g.name, name, false)
+
+	if help := opt.Help(); help != "" {
+		fmt.Fprintf(c.promptOut, "%s: %s\n", flag, help)
+	}
+
+	var _default string
+	if v := opt.Default(); v != nil {
+		_default = fmt.Sprintf("%v", v)
+	}
+	if _default != "" && !sensitive {
+		fmt.Fprintf(c.promptOut, "  default: %s\n", _default)
+	}
+
+	if vc, ok := opt.(ValidatorChainOpt); ok {
+		for _, v := range vc.GetValidators() {
+			if cv, ok := v.(ChoicesValidator); ok {
+				fmt.Fprintf(c.promptOut, "  choices: %s\n", strings.Join(cv.Choices(), ", "))
+			}
+		}
+	}
+
+	for {
+		if _default == "" || sensitive {
+			fmt.Fprintf(c.promptOut, "%s: ", flag)
+		} else {
+			fmt.Fprintf(c.promptOut, "%s [%s]: ", flag, _default)
+		}
+
+		var line string
+		if line, err = c.readPrompt(sensitive); err != nil {
+			return fmt.Errorf("the option '%s' in the group '%s' has no value: %s",
+				name, g.name, err)
+		}
+
+		var value interface{} = line
+		if line == "" {
+			if _default == "" {
+				fmt.Fprintln(c.promptOut, "the value must not be empty")
+				continue
+			}
+			value = opt.Default()
+		}
+
+		if value, err = g.parseOptValue(name, value); err != nil {
+			fmt.Fprintf(c.promptOut, "invalid value: %s\n", err)
+			continue
+		}
+
+		return g.setLayerValue(Layer{Priority: 0, Source: "prompt", Value: value}, name, sensitive)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	in := strings.NewReader("trace\ndebug\n\nsecret\n\n")
+	out := bytes.NewBuffer(nil)
+
+	password := Str("password", "", "").(RequiredSetter).SetRequired(true)
+	conf := NewConfig().EnablePrompt(in, out)
+	conf.RegisterOpts("", []Opt{
+		Str("level", "", "the log level").(RequiredSetter).SetRequired(true).
+			AddValidators(NewStrArrayValidator([]string{"debug", "info"})),
+		Int("port", 80, "the port").(RequiredSetter).SetRequired(true),
+		password.(SensitiveSetter).SetSensitive(true),
+	})
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := conf.String("level"); v != "debug" {
+		t.Errorf("expect '%s', but got '%s'", "debug", v)
+	}
+	if v := conf.Int("port"); v != 80 {
+		t.Errorf("expect %d, but got %d", 80, v)
+	}
+	if v := conf.String("password"); v != "secret" {
+		t.Errorf("expect '%s', but got '%s'", "secret", v)
+	}
+
+	output := out.String()
+	for _, s := range []string{"level: the log level\n", "  choices: debug, info\n",
+		"invalid value: ", "port [80]: ", "password: ", "the value must not be empty"} {
+		if !strings.Contains(output, s) {
+			t.Errorf("the prompt does not contain '%s':\n%s", s, output)
+		}
+	}
+
+	// No more input.
+	conf = NewConfig().EnablePrompt(strings.NewReader(""), out)
+	conf.RegisterOpt("", Str("name", "", "").(RequiredSetter).SetRequired(true))
+	if err := conf.Parse(); err == nil || !strings.Contains(err.Error(), "has no value") {
+		t.Errorf("expect the error of no value, but got %v", err)
+	}
+}
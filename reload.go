@@ -0,0 +1,284 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Reload re-runs the Pre, Parse and Post methods of all the parsers except
+// the CLI parsers after parsing, such as re-reading the configuration files,
+// and returns the changes of the option values.
+//
+// The parsers are called with a staging copy of the configuration, so the
+// values from them are collected into a fresh state instead of the current
+// one. Then the required options and the defaults are checked without
+// prompting, and the state validators are called against the complete result.
+// If all of them succeed, only the changed options are applied atomically,
+// which fires the observers of Observe and ObserveChanges. Or nothing is
+// changed.
+//
+// The CLI parsers are not called again, so the values from the CLI, including
+// those of the commands, and the positional arguments are unchanged. The values
+// set at runtime, such as SetOptValue, SetLayerValue and Txn, and the prompted
+// values are preserved, but the former are dropped if dropRuntime is true.
+// See Layer.Runtime.
+//
+// Setting the option values, such as SetOptValue and Txn.Commit, is blocked
+// until reloading finishes.
+//
+// Notice: the Pre methods of the parsers must be reenterable, for example,
+// they may only register the options which have been registered.
+//
+// If not parsed, it will panic when calling it.
+func (c *Config) Reload(dropRuntime bool) (changes []Change, err error) {
+	c.panicIsParsed(false)
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+
+	c.txnLock.Lock()
+	locked := true
+	defer func() {
+		if locked {
+			c.txnLock.Unlock()
+		}
+	}()
+
+	c.debug("Reloading the configuration")
+	groups := c.getSortedGroups(false)
+	stage := c.newStageConfig(groups, dropRuntime)
+	err = stage.initParsers()
+	if err == nil {
+		err = stage.callParsers()
+	}
+	c.mergeParserStatus(stage)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, g := range groups {
+		if err = stage.groups[g.name].checkRequiredOption(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate the complete result.
+	owners := make([]*OptGroup, 0, 8)
+	for _, g := range groups {
+		g.lock.RLock()
+		for _, change := range g.diffStage(stage.groups[g.name]) {
+			changes = append(changes, change)
+			owners = append(owners, g)
+		}
+		g.lock.RUnlock()
+	}
+	if err = c.validateState(c.buildSnapshot(0).withChanges(changes)); err != nil {
+		return nil, err
+	}
+
+	// Apply the result.
+	for _, g := range groups {
+		g.lock.Lock()
+	}
+	for _, g := range groups {
+		g.applyStage(stage.groups[g.name])
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		groups[i].lock.Unlock()
+	}
+	c.updateSnapshot()
+
+	locked = false
+	c.txnLock.Unlock()
+
+	// Notify the changes.
+	for i, change := range changes {
+		if layer, ok := owners[i].Source(change.Name); ok {
+			owners[i].logOptValue(layer, change.Name, layer.Secret)
+		}
+		if c.watch != nil {
+			c.watch(change.Group, change.Name, change.New)
+		}
+	}
+	c.emitChanges(changes)
+	return changes, nil
+}
+
+// getSortedGroups returns all the groups in the order of the name, which is
+// also the order to lock them, including the group of the positional arguments
+// if args is true.
+func (c *Config) getSortedGroups(args bool) []*OptGroup {
+	groups := make([]*OptGroup, 0, len(c.groups)+1)
+	for _, g := range c.groups {
+		groups = append(groups, g)
+	}
+	if args && c.argGroup != nil {
+		groups = append(groups, c.argGroup)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return groups
+}
+
+// isCliParser reports whether the parser is the builtin CLI parser.
+func isCliParser(p Parser) bool {
+	_, ok := p.(cliStyler)
+	return ok
+}
+
+// newStageConfig returns a copy of the configuration with all the parsers
+// except the CLI parsers, the groups of which are the stages of the given
+// groups, so that the parsers set the values into the stages instead of
+// the current groups when reloading.
+//
+// The copy has no observers, validators and prompt, and never publishes
+// the snapshot.
+func (c *Config) newStageConfig(groups []*OptGroup, dropRuntime bool) *Config {
+	s := NewConfig()
+	s.parsed = true
+	s.reloading = true
+	s.snapPaused = 1
+
+	s.isRequired = c.isRequired
+	s.isDebug = c.isDebug
+	s.isPanic = c.isPanic
+	s.isZero = c.isZero
+
+	s.args = c.args
+	s.cliArgs = c.cliArgs
+	s.groupSep = c.groupSep
+	s.groupName = c.groupName
+	s.groupPrefix = c.groupPrefix
+	s.commands = c.commands
+	s.command = c.command
+	s.argGroup = c.argGroup
+	s.argOpts = c.argOpts
+	s.layers = c.layers
+
+	s.verifier = c.verifier
+	s.tmplFuncs = c.tmplFuncs
+	s.profiles = c.profiles
+	s.profileOpt = c.profileOpt
+	s.respDepth = c.respDepth
+	s.expandLookup = c.expandLookup
+	s.usageWriter = c.usageWriter
+
+	// The values from the CLI parsers and the prompt are always kept,
+	// and those set at runtime are kept unless dropRuntime is true.
+	keeps := make(map[string]bool, len(c.parsers)+1)
+	keeps["prompt"] = true
+	for _, parser := range c.parsers {
+		if isCliParser(parser) {
+			keeps[parser.Name()] = true
+		} else {
+			s.parsers = append(s.parsers, parser)
+		}
+	}
+
+	for _, g := range groups {
+		s.groups[g.name] = g.newStage(s, func(layer Layer) bool {
+			if layer.Runtime {
+				return !dropRuntime
+			}
+			return keeps[layer.Source]
+		})
+	}
+	return s
+}
+
+// mergeParserStatus merges the status of the parsers called by the stage
+// configuration, except the number of the values.
+func (c *Config) mergeParserStatus(stage *Config) {
+	c.slock.Lock()
+	defer c.slock.Unlock()
+	for name, s := range stage.status {
+		status := c.getParserStatus(name)
+		if s.LastError != nil {
+			status.LastError = s.LastError
+			status.LastErrorTime = s.LastErrorTime
+		} else if !s.LastSuccess.IsZero() {
+			status.LastSuccess = s.LastSuccess
+			status.LastError = nil
+		}
+	}
+}
+
+// newStage returns a fresh group with the same options in the configuration
+// conf, into which the layers reported by keep are copied.
+func (g *OptGroup) newStage(conf *Config, keep func(Layer) bool) *OptGroup {
+	stage := newOptGroup(g.name, g.fname, conf)
+
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	for name, opt := range g.opts {
+		stage.opts[name] = &option{isCli: opt.isCli, opt: opt.opt, prio: 1 << 31, cmd: opt.cmd}
+
+		// Copy the layers reversely to keep the order at the same priority.
+		for i := len(opt.layers) - 1; i >= 0; i-- {
+			if keep(opt.layers[i]) {
+				stage.applyLayer(opt.layers[i], name)
+			}
+		}
+	}
+
+	return stage
+}
+
+// diffStage returns the changes from the current values to those of the stage,
+// which must be called with the lock.
+func (g *OptGroup) diffStage(stage *OptGroup) (changes []Change) {
+	names := make([]string, 0, len(stage.opts))
+	for name := range stage.opts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		old, hasOld := g.values[name]
+		_new, hasNew := stage.values[name]
+		if hasOld != hasNew || !reflect.DeepEqual(old, _new) {
+			changes = append(changes, Change{Group: g.name, Name: name, Old: old, New: _new})
+		}
+	}
+	return
+}
+
+// applyStage replaces the layers and the values of the options with those
+// of the stage, which must be called with the lock.
+func (g *OptGroup) applyStage(stage *OptGroup) {
+	for name, sopt := range stage.opts {
+		opt := g.opts[name]
+		opt.layers = sopt.layers
+		opt.prio = sopt.prio
+
+		value, ok := stage.values[name]
+		if ok {
+			g.values[name] = value
+		} else {
+			delete(g.values, name)
+		}
+
+		if opt.field.IsValid() {
+			if ok {
+				opt.field.Set(reflect.ValueOf(value))
+			} else {
+				opt.field.Set(reflect.Zero(opt.field.Type()))
+			}
+		}
+	}
+}
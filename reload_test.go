@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	file, err := ioutil.TempFile("", "go-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	writeFile := func(data string) {
+		if err := ioutil.WriteFile(file.Name(), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("[DEFAULT]\nport = 9090\n[pool]\nmin = 1\nmax = 10\n")
+
+	var batches [][]Change
+	conf := NewConfig().AddParser(NewFlagCliParser(nil, true), NewSimpleIniParser("config-file"))
+	conf.RegisterOpts("", []Opt{Int("port", 80, ""), Str("name", "", ""), Str("addr", "", "")})
+	conf.RegisterOpts("pool", []Opt{Int("min", 0, ""), Int("max", 0, "")})
+	conf.RegisterCliOpt("", Str("level", "info", ""))
+	conf.ObserveChanges(func(changes []Change) { batches = append(batches, changes) })
+	conf.RegisterStateValidator(func(s *Snapshot) error {
+		pool := s.Group("pool")
+		if min, max := pool.Int("min"), pool.Int("max"); min > max {
+			return fmt.Errorf("pool.min %d is greater than pool.max %d", min, max)
+		}
+		return nil
+	})
+	if err = conf.Parse("-config-file", file.Name(), "-level", "warn"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set the values at runtime.
+	if err = conf.SetOptValue(0, "", "name", "runtime"); err != nil {
+		t.Fatal(err)
+	}
+	batches = nil
+
+	// Reload nothing changed.
+	if changes, err := conf.Reload(false); err != nil {
+		t.Fatal(err)
+	} else if len(changes) != 0 || len(batches) != 0 {
+		t.Errorf("unexpected changes %v", changes)
+	}
+
+	// Reload the invalid state.
+	writeFile("[DEFAULT]\nport = 8080\n[pool]\nmin = 20\nmax = 10\n")
+	if _, err = conf.Reload(false); err == nil {
+		t.Error("expect an error, but got nil")
+	} else if v := conf.Int("port"); v != 9090 {
+		t.Errorf("expect the port %d, but got %d", 9090, v)
+	}
+
+	// Reload the changed file.
+	writeFile("[DEFAULT]\nport = 8080\naddr = 127.0.0.1\n[pool]\nmin = 1\nmax = 20\n")
+	changes, err := conf.Reload(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{
+		{Group: DefaultGroupName, Name: "addr", Old: "", New: "127.0.0.1"},
+		{Group: DefaultGroupName, Name: "port", Old: 9090, New: 8080},
+		{Group: "pool", Name: "max", Old: 10, New: 20},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expect the changes %v, but got %v", expected, changes)
+	} else if len(batches) != 1 || !reflect.DeepEqual(batches[0], expected) {
+		t.Errorf("unexpected the batches %v", batches)
+	}
+
+	if v := conf.Int("port"); v != 8080 {
+		t.Errorf("expect the port %d, but got %d", 8080, v)
+	} else if v := conf.Snapshot().Group("").Int("port"); v != 8080 {
+		t.Errorf("expect the port %d in the snapshot, but got %d", 8080, v)
+	} else if v := conf.String("name"); v != "runtime" {
+		t.Errorf("expect the name '%s', but got '%s'", "runtime", v)
+	} else if v := conf.String("level"); v != "warn" {
+		t.Errorf("expect the level '%s', but got '%s'", "warn", v)
+	}
+
+	if layer, ok := conf.Group("").Source("port"); !ok || layer.Source != "ini" || layer.Origin.Line != 2 {
+		t.Errorf("unexpected the source %+v", layer)
+	}
+
+	// Reload with dropping the runtime values.
+	writeFile("[DEFAULT]\nport = 8080\n[pool]\nmin = 1\nmax = 20\n")
+	changes, err = conf.Reload(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []Change{
+		{Group: DefaultGroupName, Name: "addr", Old: "127.0.0.1", New: ""},
+		{Group: DefaultGroupName, Name: "name", Old: "runtime", New: ""},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expect the changes %v, but got %v", expected, changes)
+	} else if v := conf.String("level"); v != "warn" {
+		t.Errorf("expect the level '%s', but got '%s'", "warn", v)
+	}
+}
+
+func TestReloadKeepSources(t *testing.T) {
+	newClis := []func() Parser{
+		func() Parser { return NewFlagCliParser(nil, true) },
+		func() Parser { return NewGetoptCliParser(true) },
+		func() Parser { return NewPflagCliParser(nil, true) },
+	}
+	for _, newCli := range newClis {
+		cli := newCli()
+		conf := newCommandConfig(newCli)
+		conf.AddLayer("etcd", 50)
+		conf.RegisterOpts("", []Opt{Str("title", "", ""), Str("host", "", "")})
+		if err := conf.Parse("serve", "--port", "81"); err != nil {
+			t.Fatalf("%s: %s", cli.Name(), err)
+		}
+
+		if err := conf.SetOptValue(5, "", "title", "runtime"); err != nil {
+			t.Fatal(err)
+		} else if err = conf.SetLayerValue("etcd", "", "host", "etcd"); err != nil {
+			t.Fatal(err)
+		}
+
+		if changes, err := conf.Reload(false); err != nil {
+			t.Fatalf("%s: %s", cli.Name(), err)
+		} else if len(changes) != 0 {
+			t.Errorf("%s: unexpected changes %v", cli.Name(), changes)
+		}
+
+		if v := conf.Int("port"); v != 81 {
+			t.Errorf("%s: expect the port %d, but got %d", cli.Name(), 81, v)
+		} else if v := conf.String("title"); v != "runtime" {
+			t.Errorf("%s: expect the title '%s', but got '%s'", cli.Name(), "runtime", v)
+		} else if v := conf.String("host"); v != "etcd" {
+			t.Errorf("%s: expect the host '%s', but got '%s'", cli.Name(), "etcd", v)
+		}
+	}
+}
+
+func TestReloadKeepRuntimeWithParserName(t *testing.T) {
+	os.Setenv("RELOAD_OPT", "env")
+	defer os.Unsetenv("RELOAD_OPT")
+
+	conf := NewConfig().AddParser(NewEnvVarParser("reload"))
+	conf.RegisterOpt("", Str("opt", "def", ""))
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The source of the runtime value is "env", the same as the parser.
+	if err := conf.SetOptValue(10, "", "opt", "runtime"); err != nil {
+		t.Fatal(err)
+	} else if layer, _ := conf.Group("").Source("opt"); layer.Source != "env" || !layer.Runtime {
+		t.Errorf("unexpected the source %+v", layer)
+	}
+
+	if _, err := conf.Reload(false); err != nil {
+		t.Fatal(err)
+	} else if v := conf.String("opt"); v != "runtime" {
+		t.Errorf("expect the opt '%s', but got '%s'", "runtime", v)
+	}
+
+	if _, err := conf.Reload(true); err != nil {
+		t.Fatal(err)
+	} else if v := conf.String("opt"); v != "env" {
+		t.Errorf("expect the opt '%s', but got '%s'", "env", v)
+	}
+}
+
+type writerParser struct {
+	conf *Config
+	done chan struct{}
+}
+
+func (p *writerParser) Name() string         { return "writer" }
+func (p *writerParser) Priority() int        { return 100 }
+func (p *writerParser) Pre(c *Config) error  { return nil }
+func (p *writerParser) Post(c *Config) error { return nil }
+func (p *writerParser) Parse(c *Config) error {
+	if c.reloading {
+		// Set the value at runtime concurrently when reloading.
+		p.done = make(chan struct{})
+		go func() {
+			defer close(p.done)
+			p.conf.SetOptValue(0, "", "port", 8080)
+		}()
+	}
+	return c.SetOptValue(p.Priority(), "", "port", 9090)
+}
+
+func TestReloadBlockWriters(t *testing.T) {
+	conf := NewConfig()
+	parser := &writerParser{conf: conf}
+	conf.AddParser(parser).RegisterOpt("", Int("port", 80, ""))
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The value set when reloading is neither lost nor overridden.
+	if _, err := conf.Reload(false); err != nil {
+		t.Fatal(err)
+	}
+	<-parser.done
+
+	if v := conf.Int("port"); v != 8080 {
+		t.Errorf("expect the port %d, but got %d", 8080, v)
+	}
+}
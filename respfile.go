@@ -0,0 +1,163 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// DefaultResponseFileDepth is the default maximum depth of the nested
+// response files.
+const DefaultResponseFileDepth = 8
+
+// EnableResponseFile enables to expand the CLI argument "@path" into
+// the arguments read from the response file path before the CLI parsers
+// parse them, so the expanded arguments are returned by CliArgs().
+//
+// If maxDepth is not positive, it is DefaultResponseFileDepth.
+// See ExpandResponseFiles.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) EnableResponseFile(maxDepth int) *Config {
+	c.panicIsParsed(true)
+	if maxDepth <= 0 {
+		maxDepth = DefaultResponseFileDepth
+	}
+	c.respDepth = maxDepth
+	return c
+}
+
+// ExpandResponseFiles expands the argument "@path" in args into the arguments
+// read from the response file path, and returns the new arguments.
+//
+// The arguments in the response file are separated by the whitespaces,
+// including the newline, and support the shell-like quoting:
+//
+//    'single quoted'   // All the characters are literal.
+//    "double quoted"   // The backslash only escapes '"', '\', '$' and '`'.
+//    escaped\ space    // The backslash escapes any character out of the quotes.
+//    # comment         // The comment starts with "#" and ends at the newline.
+//
+// The response file may contain the argument "@path" to include another one,
+// the maximum depth of which is maxDepth. The relative path is relative to
+// the current working directory.
+//
+// The argument "@@xxx" is unescaped to "@xxx" without expanding, and all the
+// arguments after the argument "--" are not expanded.
+func ExpandResponseFiles(args []string, maxDepth int) ([]string, error) {
+	e := respFileExpander{maxDepth: maxDepth}
+	return e.expand(args, 0)
+}
+
+type respFileExpander struct {
+	maxDepth int
+	ended    bool
+}
+
+func (e *respFileExpander) expand(args []string, depth int) ([]string, error) {
+	results := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case e.ended || len(arg) < 2 || arg[0] != '@':
+			if arg == "--" {
+				e.ended = true
+			}
+			results = append(results, arg)
+		case arg[1] == '@':
+			results = append(results, arg[1:])
+		default:
+			filename := arg[1:]
+			if depth >= e.maxDepth {
+				return nil, fmt.Errorf("the response file '%s' is nested too deeply, max %d",
+					filename, e.maxDepth)
+			}
+
+			data, err := ioutil.ReadFile(filename)
+			if err != nil {
+				return nil, err
+			}
+			_args, err := splitResponseFile(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("invalid response file '%s': %s", filename, err)
+			}
+			if _args, err = e.expand(_args, depth+1); err != nil {
+				return nil, err
+			}
+			results = append(results, _args...)
+		}
+	}
+	return results, nil
+}
+
+func splitResponseFile(data string) (args []string, err error) {
+	var quote rune
+	var inArg, escaped, comment bool
+	buf := make([]rune, 0, 32)
+
+	for _, r := range data {
+		switch {
+		case comment:
+			if r == '\n' {
+				comment = false
+			}
+		case escaped:
+			escaped = false
+			switch {
+			case r == '\n': // The escaped newline is the line continuation.
+			case quote == '"' && r != '"' && r != '\\' && r != '$' && r != '`':
+				buf = append(buf, '\\', r)
+			default:
+				buf = append(buf, r)
+			}
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				buf = append(buf, r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				buf = append(buf, r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			if inArg {
+				args = append(args, string(buf))
+				buf, inArg = buf[:0], false
+			}
+		case r == '#' && !inArg:
+			comment = true
+		default:
+			buf, inArg = append(buf, r), true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %c", quote)
+	} else if escaped {
+		return nil, fmt.Errorf("unterminated escape at the end")
+	} else if inArg {
+		args = append(args, string(buf))
+	}
+	return
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitResponseFile(t *testing.T) {
+	data := `
+# The comment line
+--opt1 value1   # The trailing comment
+--opt2 'single quoted # not comment'
+--opt3 "double \"quoted\" \n"
+--opt4 escaped\ space a#b
+--opt5 con\
+tinued ""
+`
+	expected := []string{
+		"--opt1", "value1",
+		"--opt2", "single quoted # not comment",
+		"--opt3", `double "quoted" \n`,
+		"--opt4", "escaped space", "a#b",
+		"--opt5", "continued", "",
+	}
+
+	args, err := splitResponseFile(data)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expect %q, but got %q", expected, args)
+	}
+
+	if _, err = splitResponseFile(`--opt "unterminated`); err == nil {
+		t.Error("expect an error for the unterminated quote")
+	}
+}
+
+func TestExpandResponseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file1 := filepath.Join(dir, "args1")
+	file2 := filepath.Join(dir, "args2")
+	ioutil.WriteFile(file1, []byte("-a 1 @"+file2+" -d 4"), 0644)
+	ioutil.WriteFile(file2, []byte("-b 2\n-c 3\n"), 0644)
+
+	args, err := ExpandResponseFiles([]string{"@" + file1, "@@literal", "--", "@" + file2}, 2)
+	expected := []string{"-a", "1", "-b", "2", "-c", "3", "-d", "4", "@literal", "--", "@" + file2}
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expect %q, but got %q", expected, args)
+	}
+
+	// Recursion
+	ioutil.WriteFile(file2, []byte("@"+file1), 0644)
+	if _, err = ExpandResponseFiles([]string{"@" + file1}, 8); err == nil {
+		t.Error("expect an error for the too deep response files")
+	}
+}
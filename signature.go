@@ -0,0 +1,222 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SignatureFileSuffix is the suffix of the detached signature file,
+// that's, the signature of "app.ini" is in "app.ini.sig".
+var SignatureFileSuffix = ".sig"
+
+const (
+	signatureBegin = "# -----BEGIN ED25519 SIGNATURE-----"
+	signatureEnd   = "# -----END ED25519 SIGNATURE-----"
+)
+
+var (
+	// ErrNoSignature is an error that the config file has not been signed.
+	ErrNoSignature = fmt.Errorf("no signature")
+
+	// ErrInvalidSignature is an error that the signature of the config file
+	// does not match any trusted key.
+	ErrInvalidSignature = fmt.Errorf("the signature does not match any trusted key")
+)
+
+// SignatureError stands for an error that the config file fails to be verified.
+type SignatureError struct {
+	File string
+	Err  error
+}
+
+// Error implements the interface Error.
+func (e SignatureError) Error() string {
+	return fmt.Sprintf("failed to verify the config file '%s': %v", e.File, e.Err)
+}
+
+type ed25519Verifier struct {
+	keys []ed25519.PublicKey
+}
+
+// NewEd25519Verifier returns a new FileVerifier to verify the config file by
+// the trusted ed25519 public keys. The file is trusted only if its signature
+// is signed by one of the keys.
+//
+// The signature is either detached in the file with the suffix
+// SignatureFileSuffix, such as "app.ini.sig", or embedded at the end of
+// the config file as a block of the comment lines, such as
+//
+//    # -----BEGIN ED25519 SIGNATURE-----
+//    # BASE64_ENCODED_SIGNATURE
+//    # -----END ED25519 SIGNATURE-----
+//
+// which signs the whole content before the block. If the embedded signature
+// exists, the detached one will be ignored.
+//
+// The base name of the config file is signed together with the content,
+// so the signed file cannot be renamed or swapped with another signed file.
+//
+// The signature is encoded by the standard base64, and you can use
+// SignEd25519 or EmbedEd25519Signature to generate it.
+func NewEd25519Verifier(keys ...ed25519.PublicKey) FileVerifier {
+	if len(keys) == 0 {
+		panic(fmt.Errorf("no trusted ed25519 public key"))
+	}
+	for _, key := range keys {
+		if len(key) != ed25519.PublicKeySize {
+			panic(fmt.Errorf("invalid ed25519 public key"))
+		}
+	}
+	return ed25519Verifier{keys: keys}
+}
+
+func (v ed25519Verifier) Verify(filename string, data []byte) ([]byte, error) {
+	content, sig, found, err := splitEd25519Signature(data)
+	if err != nil {
+		return nil, SignatureError{File: filename, Err: err}
+	}
+
+	if !found {
+		sigfile := filename + SignatureFileSuffix
+		bs, err := ioutil.ReadFile(sigfile)
+		if os.IsNotExist(err) {
+			return nil, SignatureError{File: filename, Err: ErrNoSignature}
+		} else if err != nil {
+			return nil, SignatureError{File: filename, Err: err}
+		}
+		if sig, err = decodeEd25519Signature(string(bs)); err != nil {
+			return nil, SignatureError{File: filename,
+				Err: fmt.Errorf("invalid signature file '%s': %s", sigfile, err)}
+		}
+	}
+
+	for _, key := range v.keys {
+		if ed25519.Verify(key, ed25519Payload(filename, content), sig) {
+			return content, nil
+		}
+	}
+	return nil, SignatureError{File: filename, Err: ErrInvalidSignature}
+}
+
+// ed25519Payload returns the signed payload of the config file, which is
+// the base name of the file and the content.
+func ed25519Payload(filename string, content []byte) []byte {
+	name := filepath.Base(filename)
+	payload := make([]byte, 0, len(name)+len(content)+1)
+	payload = append(payload, name...)
+	payload = append(payload, 0)
+	return append(payload, content...)
+}
+
+func decodeEd25519Signature(s string) ([]byte, error) {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	} else if len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("the signature size is %d, not %d",
+			len(sig), ed25519.SignatureSize)
+	}
+	return sig, nil
+}
+
+// splitEd25519Signature splits the config file content into the signed
+// content and the embedded signature.
+func splitEd25519Signature(data []byte) (content, sig []byte, found bool, err error) {
+	index := bytes.LastIndex(data, []byte(signatureBegin))
+	if index == -1 || (index > 0 && data[index-1] != '\n') {
+		return data, nil, false, nil
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data[index:])), "\n")
+	if len(lines) != 3 || strings.TrimSpace(lines[2]) != signatureEnd {
+		return nil, nil, true, fmt.Errorf("the embedded signature block is malformed")
+	}
+
+	encoded := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[1]), "#"))
+	if sig, err = decodeEd25519Signature(encoded); err != nil {
+		return nil, nil, true, fmt.Errorf("invalid embedded signature: %s", err)
+	}
+	return data[:index], sig, true, nil
+}
+
+// SignEd25519 signs the content, data, of the config file named filename
+// by the ed25519 private key, and returns the detached signature, which should
+// be saved into the file with the suffix SignatureFileSuffix.
+//
+// Only the base name of filename is signed, so the file may be moved into
+// another directory, but not be renamed.
+func SignEd25519(key ed25519.PrivateKey, filename string, data []byte) []byte {
+	sig := ed25519.Sign(key, ed25519Payload(filename, data))
+	return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+// EmbedEd25519Signature signs the content, data, of the config file named
+// filename by the ed25519 private key, and returns the new content with
+// the signature block appended. See SignEd25519.
+//
+// If data has contained an embedded signature block, it will be replaced.
+func EmbedEd25519Signature(key ed25519.PrivateKey, filename string, data []byte) ([]byte, error) {
+	content, _, _, err := splitEd25519Signature(data)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	buf.Write(content)
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	sig := ed25519.Sign(key, ed25519Payload(filename, buf.Bytes()))
+	fmt.Fprintf(buf, "%s\n# %s\n%s\n", signatureBegin,
+		base64.StdEncoding.EncodeToString(sig), signatureEnd)
+	return buf.Bytes(), nil
+}
+
+// ParseEd25519PublicKey parses the ed25519 public key encoded by the standard
+// base64.
+func ParseEd25519PublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	} else if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("the public key size is %d, not %d",
+			len(key), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// ParseEd25519PrivateKey parses the ed25519 private key encoded by the standard
+// base64.
+func ParseEd25519PrivateKey(s string) (ed25519.PrivateKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	} else if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("the private key size is %d, not %d",
+			len(key), ed25519.PrivateKeySize)
+	}
+	return ed25519.PrivateKey(key), nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEd25519Verifier(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "go-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := []byte("[DEFAULT]\nopt = value\n")
+	filename := filepath.Join(dir, "app.ini")
+	verifier := NewEd25519Verifier(pub)
+
+	// No signature
+	if _, err = verifier.Verify(filename, data); err == nil {
+		t.Error("expect an error for no signature")
+	}
+
+	// Detached signature
+	sigfile := filename + SignatureFileSuffix
+	if err = ioutil.WriteFile(sigfile, SignEd25519(priv, filename, data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := verifier.Verify(filename, data); err != nil {
+		t.Error(err)
+	} else if string(content) != string(data) {
+		t.Errorf("unexpected content '%s'", content)
+	}
+	if _, err = verifier.Verify(filename, []byte("[DEFAULT]\nopt = other\n")); err == nil {
+		t.Error("expect an error for the tampered file")
+	}
+	os.Remove(sigfile)
+
+	// Embedded signature
+	signed, err := EmbedEd25519Signature(priv, filename, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, err := verifier.Verify(filename, signed); err != nil {
+		t.Error(err)
+	} else if string(content) != string(data) {
+		t.Errorf("unexpected content '%s'", content)
+	}
+	signed[len("[DEFAULT]\nopt = ")] = 'V'
+	if _, err = verifier.Verify(filename, signed); err == nil {
+		t.Error("expect an error for the tampered file")
+	}
+
+	// Swapped with another signed file
+	signed, _ = EmbedEd25519Signature(priv, filepath.Join(dir, "other.ini"), data)
+	if _, err = verifier.Verify(filename, signed); err == nil {
+		t.Error("expect an error for the swapped file")
+	}
+	if _, err = verifier.Verify(filepath.Join(dir, "sub", "other.ini"), signed); err != nil {
+		t.Error(err)
+	}
+
+	// Untrusted key
+	other, _, _ := ed25519.GenerateKey(nil)
+	signed, _ = EmbedEd25519Signature(priv, filename, data)
+	if _, err = NewEd25519Verifier(other).Verify(filename, signed); err == nil {
+		t.Error("expect an error for the untrusted key")
+	}
+}
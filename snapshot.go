@@ -0,0 +1,222 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Snapshot is an immutable view of the values of all the options at a moment,
+// which is published by the atomic swap on each change of the option values.
+// So the reader, such as the request handler, may get a snapshot by
+// Config.Snapshot and read a consistent view from it without any lock.
+//
+// Notice: the slice and map values are shared with the configuration, so they
+// should not be modified.
+type Snapshot struct {
+	version uint64
+	prefix  string
+	dgroup  string
+	groups  map[string]*SnapshotGroup
+}
+
+// SnapshotGroup is the immutable view of the values of the options
+// in a group, which has the same typed getters as OptGroup.
+type SnapshotGroup struct {
+	valueGetter
+
+	name   string
+	fname  string
+	values map[string]interface{}
+}
+
+func newSnapshotGroup(name, fullName string, values map[string]interface{}) *SnapshotGroup {
+	g := &SnapshotGroup{name: name, fname: fullName, values: values}
+	g.valueGetter = valueGetter{group: name, value: g.Value}
+	return g
+}
+
+// Snapshot returns the latest snapshot of the option values, which is
+// lock-free and may be called concurrently.
+//
+// If not parsed, it returns the snapshot of the values set so far.
+func (c *Config) Snapshot() *Snapshot {
+	if s, ok := c.snapshot.Load().(*Snapshot); ok {
+		return s
+	}
+	return c.publishSnapshot()
+}
+
+// publishSnapshot builds the snapshot of the current option values,
+// and publishes it by the atomic swap.
+func (c *Config) publishSnapshot() *Snapshot {
+	c.snapLock.Lock()
+	defer c.snapLock.Unlock()
+
+	c.snapVersion++
+	s := c.buildSnapshot(c.snapVersion)
+	c.snapshot.Store(s)
+	return s
+}
+
+// buildSnapshot builds the snapshot of the current option values,
+// which holds the locks of all the groups in order to copy a consistent view.
+func (c *Config) buildSnapshot(version uint64) *Snapshot {
+	s := &Snapshot{
+		version: version,
+		prefix:  c.groupPrefix,
+		dgroup:  c.groupName,
+		groups:  make(map[string]*SnapshotGroup, len(c.groups)+1),
+	}
+
+	groups := c.getSortedGroups(true)
+	for _, g := range groups {
+		g.lock.RLock()
+	}
+	for key, g := range c.groups {
+		s.groups[key] = g.snapshot()
+	}
+	if c.argGroup != nil {
+		s.groups[ArgGroupName] = c.argGroup.snapshot()
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		groups[i].lock.RUnlock()
+	}
+	return s
+}
+
+// snapshot returns the view of the values of the group,
+// which must be called with the lock.
+func (g *OptGroup) snapshot() *SnapshotGroup {
+	values := make(map[string]interface{}, len(g.values))
+	for name, value := range g.values {
+		values[name] = value
+	}
+	return newSnapshotGroup(g.name, g.fname, values)
+}
+
+// withChanges returns a copy of the snapshot with the changes applied,
+// which shares the values of the unchanged groups.
+func (s *Snapshot) withChanges(changes []Change) *Snapshot {
+	ns := &Snapshot{
+		version: s.version,
+		prefix:  s.prefix,
+		dgroup:  s.dgroup,
+		groups:  make(map[string]*SnapshotGroup, len(s.groups)),
+	}
+
+	copied := make(map[*SnapshotGroup]*SnapshotGroup, len(changes))
+	for key, g := range s.groups {
+		ns.groups[key] = g
+	}
+
+	for _, change := range changes {
+		g := s.groups[change.Group]
+		if g == nil {
+			continue
+		}
+
+		ng := copied[g]
+		if ng == nil {
+			ng = newSnapshotGroup(g.name, g.fname, make(map[string]interface{}, len(g.values)))
+			for name, value := range g.values {
+				ng.values[name] = value
+			}
+			copied[g] = ng
+		}
+		if change.New == nil {
+			delete(ng.values, change.Name)
+		} else {
+			ng.values[change.Name] = change.New
+		}
+	}
+
+	for key, g := range ns.groups {
+		if ng := copied[g]; ng != nil {
+			ns.groups[key] = ng
+		}
+	}
+	return ns
+}
+
+// updateSnapshot publishes the new snapshot after the option value is changed,
+// which is delayed to the end when parsing.
+func (c *Config) updateSnapshot() {
+	if atomic.LoadInt32(&c.snapPaused) == 0 {
+		c.publishSnapshot()
+	}
+}
+
+// Version returns the version of the snapshot, which is increased by one
+// when publishing a new snapshot.
+func (s *Snapshot) Version() uint64 {
+	return s.version
+}
+
+// HasGroup reports whether the snapshot contains the group named name.
+func (s *Snapshot) HasGroup(name string) bool {
+	_, ok := s.groups[s.getGroupName(name)]
+	return ok
+}
+
+// Group returns the view of the group named name, which is the same as
+// Config.Group. The group ArgGroupName is the positional arguments.
+//
+// Return an empty group if the group does not exist, the getters of which
+// return an error or panic.
+func (s *Snapshot) Group(name string) *SnapshotGroup {
+	name = s.getGroupName(name)
+	if g, ok := s.groups[name]; ok {
+		return g
+	}
+	return newSnapshotGroup(name, name, nil)
+}
+
+// G is the short for s.Group(name).
+func (s *Snapshot) G(name string) *SnapshotGroup {
+	return s.Group(name)
+}
+
+func (s *Snapshot) getGroupName(name string) string {
+	if name = strings.TrimPrefix(name, s.prefix); name == "" {
+		return s.dgroup
+	}
+	return name
+}
+
+// Name returns the name of the group.
+func (g *SnapshotGroup) Name() string {
+	return g.name
+}
+
+// FullName returns the full name of the group.
+func (g *SnapshotGroup) FullName() string {
+	return g.fname
+}
+
+// Value returns the value of the option.
+//
+// Return nil if the option does not exist.
+func (g *SnapshotGroup) Value(name string) interface{} {
+	return g.values[name]
+}
+
+// V is the short for g.Value(name).
+func (g *SnapshotGroup) V(name string) interface{} {
+	return g.Value(name)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	conf := NewConfig()
+	conf.RegisterOpts("", []Opt{Str("host", "localhost", ""), Int("port", 80, "")})
+	conf.RegisterOpt("db", Str("url", "", ""))
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	s1 := conf.Snapshot()
+	if s := conf.Snapshot(); s != s1 {
+		t.Error("the snapshot is republished without any change")
+	}
+
+	conf.SetOptValue(0, "", "port", 8080)
+	conf.SetOptValue(0, "db", "url", "mysql://")
+	s2 := conf.Snapshot()
+	if s2.Version() <= s1.Version() {
+		t.Errorf("expect the version greater than %d, but got %d", s1.Version(), s2.Version())
+	}
+
+	if v := s1.Group("").Int("port"); v != 80 {
+		t.Errorf("expect %d, but got %d", 80, v)
+	}
+	if v := s1.Group("db").String("url"); v != "" {
+		t.Errorf("expect '%s', but got '%s'", "", v)
+	}
+	if v := s2.Group("").Int("port"); v != 8080 {
+		t.Errorf("expect %d, but got %d", 8080, v)
+	}
+	if v := s2.Group("db").String("url"); v != "mysql://" {
+		t.Errorf("expect '%s', but got '%s'", "mysql://", v)
+	}
+	if v := s2.Group("").StringD("nonexistent", "default"); v != "default" {
+		t.Errorf("expect '%s', but got '%s'", "default", v)
+	}
+	if _, err := s2.Group("nonexistent").IntE("port"); err == nil {
+		t.Error("expect an error for the nonexistent group")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s := conf.Snapshot().Group("")
+				if s.Int("port") != 8080 && s.Int("port") < 9000 {
+					t.Errorf("unexpected port %d", s.Int("port"))
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		conf.SetOptValue(0, "", "port", 9000+i)
+	}
+	wg.Wait()
+}
+
+func TestSnapshotConsistent(t *testing.T) {
+	conf := NewConfig()
+	conf.RegisterOpt("a", Int("value", 0, ""))
+	conf.RegisterOpt("b", Int("value", 0, ""))
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 1000; i++ {
+			txn := conf.Begin(0)
+			txn.Set("a", "value", i)
+			txn.Set("b", "value", i)
+			if err := txn.Commit(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		s := conf.buildSnapshot(0)
+		if a, b := s.Group("a").Int("value"), s.Group("b").Int("value"); a != b {
+			t.Fatalf("the snapshot is torn: a=%d, b=%d", a, b)
+		}
+	}
+	wg.Wait()
+}
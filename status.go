@@ -0,0 +1,135 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"time"
+)
+
+// Watcher is an optional interface of the parser which watches its source
+// and keeps updating the options after parsing, such as etcd, zookeeper, etc.
+//
+// If a parser has implemented the interface, its connection state will be
+// reported by Config.Status().
+type Watcher interface {
+	// Connected reports whether the parser is connected to its source.
+	Connected() bool
+}
+
+// ParserStatus is the status of a parser, which may be exposed on the health
+// endpoint.
+type ParserStatus struct {
+	Name     string
+	Priority int
+
+	// LastSuccess is the last time when the parser parsed successfully.
+	// It's ZERO if the parser has never succeeded.
+	LastSuccess time.Time
+
+	// LastError is the last error returned by the parser, and LastErrorTime
+	// is the time when it happened. LastError is reset to nil after the parser
+	// succeeds again, but LastErrorTime is kept.
+	LastError     error
+	LastErrorTime time.Time
+
+	// Values is the number of the option values applied by the parser,
+	// which excludes the values overridden by the higher priority.
+	Values int
+
+	// Watching reports whether the parser watches its source, that's,
+	// the parser has implemented the interface Watcher. If true, Connected
+	// reports whether the parser is connected to its source.
+	Watching  bool
+	Connected bool
+}
+
+func (c *Config) getParserStatus(name string) *ParserStatus {
+	status := c.status[name]
+	if status == nil {
+		status = &ParserStatus{Name: name}
+		c.status[name] = status
+	}
+	return status
+}
+
+func (c *Config) updateParserStatus(name string, values int, err error) {
+	now := time.Now()
+
+	c.slock.Lock()
+	status := c.getParserStatus(name)
+	status.Values += values
+	if err == nil {
+		status.LastSuccess = now
+		status.LastError = nil
+	} else {
+		status.LastError = err
+		status.LastErrorTime = now
+	}
+	c.slock.Unlock()
+}
+
+func (c *Config) setCurrentParser(name string) {
+	c.slock.Lock()
+	c.current = name
+	c.slock.Unlock()
+}
+
+// countParserValue counts the option value applied by the parser
+// which is parsing.
+func (c *Config) countParserValue() {
+	c.slock.Lock()
+	if c.current != "" {
+		c.getParserStatus(c.current).Values++
+	}
+	c.slock.Unlock()
+}
+
+// UpdateParserStatus updates the status of the parser named name.
+//
+// The watching parser may call it to report the result after having applied
+// the changes of its source at runtime. values is the number of the option
+// values applied, and err is the error that occurred, which is nil on success.
+func (c *Config) UpdateParserStatus(name string, values int, err error) {
+	c.updateParserStatus(name, values, err)
+}
+
+// Status returns the status of all the parsers in turn.
+//
+// The parser that has not been run has only the name and the priority.
+func (c *Config) Status() []ParserStatus {
+	statuses := make([]ParserStatus, len(c.parsers))
+
+	c.slock.Lock()
+	for i, p := range c.parsers {
+		if status := c.status[p.Name()]; status != nil {
+			statuses[i] = *status
+		} else {
+			statuses[i].Name = p.Name()
+		}
+		statuses[i].Priority = p.Priority()
+	}
+	c.slock.Unlock()
+
+	for i, p := range c.parsers {
+		if w, ok := p.(Watcher); ok {
+			statuses[i].Watching = true
+			statuses[i].Connected = w.Connected()
+		}
+	}
+
+	return statuses
+}
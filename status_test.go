@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestParserStatus(t *testing.T) {
+	os.Setenv("PSTATUS_OPT1", "abc")
+	os.Setenv("PSTATUS_OPT2", "123")
+	defer os.Unsetenv("PSTATUS_OPT1")
+	defer os.Unsetenv("PSTATUS_OPT2")
+
+	conf := NewConfig().AddParser(NewFlagCliParser(nil, true), NewEnvVarParser("pstatus"))
+	conf.RegisterCliOpt("", Str("opt1", "", "the option 1"))
+	conf.RegisterOpt("", Int("opt2", 0, "the option 2"))
+	if err := conf.Parse("-opt1", "xyz"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The value of opt1 from env is overridden by the CLI.
+	if status := conf.Status()[1]; status.Name != "env" || status.Values != 1 {
+		t.Errorf("expect 1 value applied by env, but got %d", status.Values)
+	}
+
+	conf.UpdateParserStatus("env", 0, fmt.Errorf("test"))
+	if status := conf.Status()[1]; status.LastError == nil {
+		t.Error("expect the last error, but got nil")
+	}
+
+	conf.UpdateParserStatus("env", 0, nil)
+	if status := conf.Status()[1]; status.LastError != nil {
+		t.Errorf("unexpected the last error: %s", status.LastError)
+	} else if status.LastErrorTime.IsZero() {
+		t.Error("the last error time is reset")
+	}
+}
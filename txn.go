@@ -0,0 +1,237 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrTxnDone is returned when using the transaction which has been committed
+// or rolled back.
+var ErrTxnDone = fmt.Errorf("the transaction has been committed or rolled back")
+
+// Change is the change of the current value of the option.
+type Change struct {
+	Group string
+	Name  string
+	Old   interface{} // It's nil if the option has no value before.
+	New   interface{}
+}
+
+// ObserveChanges watches the changes of the option values in batches.
+//
+// The function f is called once with all the changes committed by
+// a transaction, or with the single change set by SetOptValue, etc.
+// See Begin.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) ObserveChanges(f func(changes []Change)) {
+	c.panicIsParsed(true)
+	c.changes = f
+}
+
+func (c *Config) emitChanges(changes []Change) {
+	if c.changes != nil && len(changes) > 0 {
+		c.changes(changes)
+	}
+}
+
+// RegisterStateValidator registers the validator to check the relationship
+// of the values of the multiple options, such as "pool.min <= pool.max",
+// which is called against the state to be applied.
+//
+// The validators are called after parsing, before committing a transaction,
+// and before setting the value by SetOptValue, etc. at runtime, which will
+// fail if the validators return an error. See Begin.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) RegisterStateValidator(v func(s *Snapshot) error) {
+	c.panicIsParsed(true)
+	c.stateValidators = append(c.stateValidators, v)
+}
+
+func (c *Config) validateState(s *Snapshot) (err error) {
+	for _, v := range c.stateValidators {
+		if err = v(s); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Txn is a transaction to change the values of the multiple options
+// atomically with the all-or-nothing validation.
+type Txn struct {
+	conf     *Config
+	priority int
+	source   string
+	runtime  bool
+	changes  []txnChange
+	err      error
+	done     bool
+}
+
+type txnChange struct {
+	group *OptGroup
+	name  string
+	value interface{}
+	layer Layer // The layer applied when committing.
+}
+
+// Begin starts a new transaction to change the option values with
+// the priority, which is the same as SetOptValue. For example,
+//
+//    txn := conf.Begin(0)
+//    txn.Set("pool", "min", 10)
+//    txn.Set("pool", "max", 20)
+//    if err := txn.Commit(); err != nil {
+//        // Nothing is changed.
+//    }
+//
+// The values are parsed and validated by the validators of the options
+// when setting, then the state validators are called against the proposed
+// state when committing. If all of them succeed, the values are applied
+// atomically, that's, the readers see all or none of them, and the observer
+// of ObserveChanges is called once with all the changes. Or nothing is
+// changed. See RegisterStateValidator.
+//
+// Notice: the observer of Observe is still called for each changed option.
+func (c *Config) Begin(priority int) *Txn {
+	txn := &Txn{conf: c, priority: priority}
+	txn.source, txn.runtime = c.getLayerSource(priority)
+	if priority < 0 {
+		txn.err = fmt.Errorf("the priority must not be the negative")
+	}
+	return txn
+}
+
+// Set parses and validates the value of the option in the group,
+// then adds it into the transaction.
+//
+// If failed, the transaction will fail to commit, and the error is returned.
+func (t *Txn) Set(groupName, optName string, optValue interface{}) (err error) {
+	if t.done {
+		return ErrTxnDone
+	}
+
+	group := t.conf.getGroupByName(groupName, false)
+	if group == nil {
+		err = fmt.Errorf("no group '%s'", groupName)
+	} else if opt, ok := group.opts[optName]; !ok {
+		err = fmt.Errorf("no the option '%s' in the group '%s'", optName, group.Name())
+	} else if !t.conf.isActiveCommand(opt.cmd) {
+		t.conf.debug("Ignore the option [%s]:[%s] of the command '%s'", group.Name(),
+			optName, opt.cmd.FullName())
+		return nil
+	} else if optValue, err = group.parseOptValue(optName, optValue); err == nil {
+		t.changes = append(t.changes, txnChange{group: group, name: optName, value: optValue})
+		return nil
+	}
+
+	if t.err == nil {
+		t.err = err
+	}
+	return
+}
+
+// Rollback discards the transaction.
+func (t *Txn) Rollback() {
+	t.done = true
+	t.changes = nil
+}
+
+// Commit validates the proposed state by the state validators, and applies
+// all the changes atomically if successfully. Or nothing is changed.
+func (t *Txn) Commit() (err error) {
+	if t.done {
+		return ErrTxnDone
+	}
+	t.done = true
+
+	if t.err != nil {
+		return t.err
+	} else if len(t.changes) == 0 {
+		return nil
+	}
+
+	c := t.conf
+	c.txnLock.Lock()
+	defer c.txnLock.Unlock()
+
+	// Collect the groups to lock them in order when applying the changes.
+	groups := make([]*OptGroup, 0, len(t.changes))
+	for _, change := range t.changes {
+		if !containsGroup(groups, change.group) {
+			groups = append(groups, change.group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+
+	// Validate the proposed state.
+	proposed := make([]Change, 0, len(t.changes))
+	for _, change := range t.changes {
+		if t.priority <= change.group.Priority(change.name) {
+			proposed = append(proposed, Change{Group: change.group.name,
+				Name: change.name, New: change.value})
+		}
+	}
+	if err = c.validateState(c.buildSnapshot(0).withChanges(proposed)); err != nil {
+		return
+	}
+
+	// Apply the changes.
+	now := time.Now()
+	applied := make([]txnChange, 0, len(t.changes))
+	changes := make([]Change, 0, len(t.changes))
+	for _, g := range groups {
+		g.lock.Lock()
+	}
+	for _, change := range t.changes {
+		change.layer = Layer{Priority: t.priority, Source: t.source, Value: change.value,
+			Time: now, Runtime: t.runtime}
+		if old, ok := change.group.applyLayer(change.layer, change.name); ok {
+			applied = append(applied, change)
+			changes = append(changes, Change{Group: change.group.name,
+				Name: change.name, Old: old, New: change.value})
+		}
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		groups[i].lock.Unlock()
+	}
+
+	// Notify the changes.
+	c.updateSnapshot()
+	for _, change := range applied {
+		change.group.logOptValue(change.layer, change.name, change.layer.Secret)
+		if c.watch != nil {
+			c.watch(change.group.name, change.name, change.value)
+		}
+	}
+	c.emitChanges(changes)
+	return nil
+}
+
+func containsGroup(groups []*OptGroup, group *OptGroup) bool {
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
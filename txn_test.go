@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTxn(t *testing.T) {
+	var batches [][]Change
+	conf := NewConfig()
+	conf.RegisterOpts("pool", []Opt{Int("min", 1, ""), Int("max", 10, "")})
+	conf.ObserveChanges(func(changes []Change) { batches = append(batches, changes) })
+	conf.RegisterStateValidator(func(s *Snapshot) error {
+		pool := s.Group("pool")
+		if min, max := pool.Int("min"), pool.Int("max"); min > max {
+			return fmt.Errorf("pool.min %d is greater than pool.max %d", min, max)
+		}
+		return nil
+	})
+	if err := conf.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setting pool.min first would pass through the invalid state.
+	batches = nil
+	txn := conf.Begin(0)
+	txn.Set("pool", "min", 20)
+	txn.Set("pool", "max", "30")
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := conf.Group("pool")
+	if min, max := pool.Int("min"), pool.Int("max"); min != 20 || max != 30 {
+		t.Errorf("expect min=%d and max=%d, but got min=%d and max=%d", 20, 30, min, max)
+	}
+	expected := [][]Change{{
+		{Group: "pool", Name: "min", Old: 1, New: 20},
+		{Group: "pool", Name: "max", Old: 10, New: 30},
+	}}
+	if !reflect.DeepEqual(batches, expected) {
+		t.Errorf("unexpected changes %v", batches)
+	}
+	if err := txn.Commit(); err != ErrTxnDone {
+		t.Errorf("expect ErrTxnDone, but got %v", err)
+	}
+
+	// The state validator fails.
+	batches = nil
+	txn = conf.Begin(0)
+	txn.Set("pool", "min", 40)
+	if err := txn.Commit(); err == nil {
+		t.Error("expect an error from the state validator")
+	}
+
+	// The option value is invalid.
+	txn = conf.Begin(0)
+	txn.Set("pool", "max", 50)
+	if err := txn.Set("pool", "min", "abc"); err == nil {
+		t.Error("expect an error for the invalid value")
+	}
+	if err := txn.Commit(); err == nil {
+		t.Error("expect an error for the invalid value")
+	}
+
+	txn = conf.Begin(0)
+	txn.Set("pool", "max", 50)
+	txn.Rollback()
+
+	if min, max := pool.Int("min"), pool.Int("max"); min != 20 || max != 30 {
+		t.Errorf("expect min=%d and max=%d, but got min=%d and max=%d", 20, 30, min, max)
+	}
+	if len(batches) != 0 {
+		t.Errorf("unexpected changes %v", batches)
+	}
+
+	// A single change is also validated by the state validators.
+	if err := conf.SetOptValue(0, "pool", "max", 10); err == nil {
+		t.Error("expect an error from the state validator")
+	} else if max := pool.Int("max"); max != 30 {
+		t.Errorf("expect max=%d, but got %d", 30, max)
+	}
+
+	// A single change is also emitted as a batch.
+	conf.SetOptValue(0, "pool", "max", 40)
+	if !reflect.DeepEqual(batches, [][]Change{{{Group: "pool", Name: "max", Old: 30, New: 40}}}) {
+		t.Errorf("unexpected changes %v", batches)
+	}
+
+	// Unsetting the value is also validated and emitted.
+	batches = nil
+	if err := conf.UnsetOptValue(0, "pool", "max"); err == nil {
+		t.Error("expect an error from the state validator")
+	} else if max := pool.Int("max"); max != 40 {
+		t.Errorf("expect max=%d, but got %d", 40, max)
+	}
+	if err := conf.UnsetOptValue(0, "pool", "min"); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(batches, [][]Change{{{Group: "pool", Name: "min", Old: 20, New: 1}}}) {
+		t.Errorf("unexpected changes %v", batches)
+	}
+}
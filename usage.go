@@ -0,0 +1,337 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"golang.org/x/term"
+)
+
+// DefaultUsageWidth is the default width of the usage if the usage writer
+// is not a terminal and the environment variable COLUMNS is not set.
+const DefaultUsageWidth = 80
+
+// DefaultUsageTemplate is the default template of the usage, the data of
+// which is Usage, and which has the extra function "wrap", such as
+// `{{wrap 8 .Width .Description}}`, to wrap the text to the width with
+// the indent.
+const DefaultUsageTemplate = `Usage: {{.Program}}{{if .Command}} {{.Command}}{{end}} [OPTIONS]{{if .Commands}} COMMAND{{end}}{{range .Args}} {{.Flag}}{{end}}
+{{if .Args}}
+Arguments:
+{{range .Args}}  {{.Name}}{{if .Type}} {{.Type}}{{end}}
+{{with wrap 8 $.Width .Description}}{{.}}
+{{end}}{{end}}{{end}}{{range .Groups}}
+{{if .Name}}Options of {{.Name}}{{else}}Options{{end}}:
+{{range .Opts}}  {{.Flag}}{{if .Type}} {{.Type}}{{end}}
+{{with wrap 8 $.Width .Description}}{{.}}
+{{end}}{{end}}{{end}}{{if .Commands}}
+Commands:
+{{range .Commands}}  {{.Name}}
+{{with wrap 8 $.Width .Help}}{{.}}
+{{end}}{{end}}{{end}}`
+
+// UsageOpt is the information of the CLI option displayed in the usage.
+type UsageOpt struct {
+	Flag        string   // The flag names, such as "-p, --port" or "-port".
+	Name        string   // The name of the option.
+	Short       string   // The short name of the option.
+	Type        string   // The value type, which is "" for the bool and counter option.
+	Help        string   // The help doc of the option.
+	Default     string   // The default value, which is "" if having no default.
+	EnvVars     []string // The names of the environment variables.
+	Required    bool     // Whether the option is required.
+	Deprecated  string   // The deprecation message.
+	Constraints []string // The descriptions of the validators.
+}
+
+// Description returns the help doc with the default, the environment
+// variables, the constraints, and the required and deprecated markers.
+func (o UsageOpt) Description() string {
+	desc := o.Help
+	if o.Default != "" {
+		desc = fmt.Sprintf("%s (default: %s)", desc, o.Default)
+	}
+	if len(o.EnvVars) > 0 {
+		desc = fmt.Sprintf("%s (env: %s)", desc, strings.Join(o.EnvVars, ", "))
+	}
+	if len(o.Constraints) > 0 {
+		desc = fmt.Sprintf("%s (%s)", desc, strings.Join(o.Constraints, ", "))
+	}
+	if o.Required {
+		desc = desc + " [required]"
+	}
+	if o.Deprecated != "" {
+		desc = fmt.Sprintf("%s [deprecated: %s]", desc, o.Deprecated)
+	}
+	return strings.TrimSpace(desc)
+}
+
+// UsageGroup is the group of the CLI options displayed in the usage.
+type UsageGroup struct {
+	Name string // It is "" for the default group.
+	Opts []UsageOpt
+}
+
+// Usage is the data to render the usage.
+type Usage struct {
+	Program  string     // The program name.
+	Command  string     // The full name of the selected command.
+	Commands []*Command // The sub-commands of the selected command.
+	Args     []UsageOpt // The positional arguments, the flag of which is like "<name>".
+	Groups   []UsageGroup
+	Width    int // The terminal width.
+}
+
+// SetUsageTemplate sets the template of the usage, which is
+// DefaultUsageTemplate by default.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) SetUsageTemplate(tmpl string) *Config {
+	c.panicIsParsed(true)
+	c.usageTmpl = template.Must(template.New("usage").Funcs(template.FuncMap{
+		"wrap": wrapText,
+	}).Parse(tmpl))
+	return c
+}
+
+// SetUsageWriter sets the writer of the usage and the warnings of
+// the deprecated options, which is os.Stderr by default.
+//
+// If parsed, it will panic when calling it.
+func (c *Config) SetUsageWriter(w io.Writer) *Config {
+	c.panicIsParsed(true)
+	c.usageWriter = w
+	return c
+}
+
+func (c *Config) getUsageWriter() io.Writer {
+	if c.usageWriter == nil {
+		return os.Stderr
+	}
+	return c.usageWriter
+}
+
+// cliStyle is the style of the CLI options of the CLI parser, which is used
+// by the usage and the completion.
+type cliStyle struct {
+	prefix string // The prefix of the long option, such as "-" or "--".
+	short  bool   // Whether to support the short option.
+	utoh   bool   // Whether to convert the underline to the hyphen.
+}
+
+var defaultCliStyle = cliStyle{prefix: "--", short: true}
+
+// cliStyler is implemented by the builtin CLI parsers to report their style.
+type cliStyler interface {
+	cliStyle() cliStyle
+}
+
+// getCliStyle returns the style of the first CLI parser, or the default.
+func (c *Config) getCliStyle() cliStyle {
+	for _, parser := range c.parsers {
+		if s, ok := parser.(cliStyler); ok {
+			return s.cliStyle()
+		}
+	}
+	return defaultCliStyle
+}
+
+// cliFlags returns the long and short flags of the option in the group,
+// such as "--port" and "-p", and the short flag is "" if not supported.
+func (c *Config) cliFlags(group string, opt Opt) (long, short string) {
+	style := c.getCliStyle()
+	long = style.prefix + cliOptName(c, group, opt.Name(), style.utoh)
+	if style.short && opt.Short() != "" {
+		short = "-" + opt.Short()
+	}
+	return
+}
+
+// usageFlag formats the flags of the option in the group for the usage.
+func (c *Config) usageFlag(group string, opt Opt) string {
+	long, short := c.cliFlags(group, opt)
+	if short != "" {
+		return fmt.Sprintf("%s, %s", short, long)
+	} else if c.getCliStyle().short {
+		return "    " + long
+	}
+	return long
+}
+
+// getUsageWidth returns the width of the terminal of the usage writer,
+// or that from the environment variable COLUMNS. Return 0 if neither.
+func (c *Config) getUsageWidth() int {
+	if f, ok := c.getUsageWriter().(*os.File); ok {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// GetUsage returns the data of the usage of the global and the selected
+// command options and positional arguments.
+func (c *Config) GetUsage() Usage {
+	usage := Usage{
+		Program:  filepath.Base(os.Args[0]),
+		Commands: c.Commands(),
+		Args:     c.getUsageArgs(),
+		Width:    DefaultUsageWidth,
+	}
+	if cmd := c.Command(); cmd != nil {
+		usage.Command = cmd.FullName()
+	}
+	if width := c.getUsageWidth(); width > 0 {
+		usage.Width = width
+	}
+
+	for _, group := range c.Groups() {
+		opts := group.CliOpts()
+		if len(opts) == 0 {
+			continue
+		}
+
+		ug := UsageGroup{Name: group.FullName()}
+		if ug.Name == c.GetDefaultGroupName() {
+			ug.Name = ""
+		}
+
+		for _, opt := range opts {
+			ug.Opts = append(ug.Opts, c.getUsageOpt(group, opt))
+		}
+		sort.Slice(ug.Opts, func(i, j int) bool { return ug.Opts[i].Name < ug.Opts[j].Name })
+		usage.Groups = append(usage.Groups, ug)
+	}
+
+	sort.Slice(usage.Groups, func(i, j int) bool {
+		return usage.Groups[i].Name < usage.Groups[j].Name
+	})
+	return usage
+}
+
+func (c *Config) getUsageOpt(group *OptGroup, opt Opt) UsageOpt {
+	uo := UsageOpt{
+		Flag:       c.usageFlag(group.FullName(), opt),
+		Name:       opt.Name(),
+		Short:      opt.Short(),
+		Help:       opt.Help(),
+		Deprecated: GetDeprecated(opt),
+		Required:   IsRequiredOpt(opt),
+	}
+
+	zero := opt.Zero()
+	if _, ok := zero.(bool); !ok && !IsCounterOpt(opt) {
+		uo.Type = fmt.Sprintf("%T", zero)
+	}
+
+	if v := opt.Default(); v != nil {
+		if !reflect.DeepEqual(v, zero) {
+			uo.Default = fmt.Sprintf("%v", v)
+		}
+	} else if c.isRequired && !c.isZero {
+		uo.Required = true
+	}
+
+	envs := make(map[string]bool, 2)
+	addEnvs := func(names []string) {
+		for _, name := range names {
+			if !envs[name] {
+				envs[name] = true
+				uo.EnvVars = append(uo.EnvVars, name)
+			}
+		}
+	}
+	addEnvs(GetEnvVars(opt))
+	for _, parser := range c.parsers {
+		if namer, ok := parser.(EnvVarNamer); ok {
+			addEnvs(namer.EnvVarNames(c, group, opt))
+		}
+	}
+
+	var validators []Validator
+	if v, ok := opt.(Validator); ok {
+		validators = append(validators, v)
+	}
+	if vc, ok := opt.(ValidatorChainOpt); ok {
+		validators = append(validators, vc.GetValidators()...)
+	}
+	for _, v := range validators {
+		if s, ok := v.(fmt.Stringer); ok {
+			uo.Constraints = append(uo.Constraints, s.String())
+		}
+	}
+
+	return uo
+}
+
+// PrintUsage prints the usage of the global and the selected command options
+// into the usage writer by the usage template.
+//
+// The CLI parsers call it when giving "-h" or "--help".
+func (c *Config) PrintUsage() error {
+	tmpl := c.usageTmpl
+	if tmpl == nil {
+		tmpl = defaultUsageTemplate
+	}
+
+	return tmpl.Execute(c.getUsageWriter(), c.GetUsage())
+}
+
+var defaultUsageTemplate = template.Must(template.New("usage").Funcs(template.FuncMap{
+	"wrap": wrapText,
+}).Parse(DefaultUsageTemplate))
+
+// wrapText wraps the text by the words to the lines not longer than width,
+// each of which is indented by indent spaces.
+func wrapText(indent, width int, text string) string {
+	prefix := strings.Repeat(" ", indent)
+	width -= indent
+	if width < 20 {
+		width = 20
+	}
+
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) > width:
+			lines = append(lines, prefix+line)
+			line = word
+		default:
+			line += " " + word
+		}
+	}
+	if line != "" {
+		lines = append(lines, prefix+line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestUsage(t *testing.T) {
+	os.Setenv("COLUMNS", "60")
+	defer os.Unsetenv("COLUMNS")
+
+	buf := bytes.NewBuffer(nil)
+	conf := NewConfig().AddParser(NewGetoptCliParser(true), NewEnvVarParser("app"))
+	conf.SetUsageWriter(buf)
+	conf.RegisterCliOpts("", []Opt{
+		StrOpt("m", "mode", "dev", "the running mode").
+			AddValidators(NewStrArrayValidator([]string{"dev", "prod"})),
+		CountOpt("v", "verbose", "the verbosity"),
+		Str("old_addr", "", "the old address").(DeprecatedSetter).SetDeprecated("use --addr instead"),
+	})
+	dburl := Str("url", "", "the url of the database").(RequiredSetter).SetRequired(true)
+	conf.RegisterCliOpt("db", dburl.(EnvVarSetter).SetEnvVars("DATABASE_URL"))
+	conf.NewCommand("serve", "Start the server")
+
+	if err := conf.Parse("--help"); err != ErrHelp {
+		t.Fatalf("expect ErrHelp, but got %v", err)
+	}
+
+	expected := `Usage: ` + conf.GetUsage().Program + ` [OPTIONS] COMMAND
+
+Options:
+  -m, --mode string
+        the running mode (default: dev) (env: APP_MODE) (one
+        of dev|prod)
+      --old-addr string
+        the old address (env: APP_OLD_ADDR) [deprecated: use
+        --addr instead]
+  -v, --verbose
+        the verbosity (env: APP_VERBOSE)
+
+Options of db:
+      --db.url string
+        the url of the database (env: DATABASE_URL,
+        APP_DB_URL) [required]
+
+Commands:
+  serve
+        Start the server
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected usage:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestRequiredOpt(t *testing.T) {
+	conf := NewConfig()
+	conf.RegisterOpt("", Str("opt", "default", "").(RequiredSetter).SetRequired(true))
+	if err := conf.Parse(); err == nil || !strings.Contains(err.Error(), "required") {
+		t.Errorf("expect the required error, but got %v", err)
+	}
+}
+
+func TestDeprecatedWarning(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	conf := NewConfig().AddParser(NewFlagCliParser(nil, false)).SetUsageWriter(buf)
+	conf.RegisterCliOpt("", Str("old_addr", "", "").(DeprecatedSetter).SetDeprecated("use -addr"))
+	if err := conf.Parse("-old_addr", "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "WARNING: the option [DEFAULT]:[old_addr] is deprecated: use -addr\n"
+	if s := buf.String(); s != expected {
+		t.Errorf("expect the warning '%s', but got '%s'", expected, s)
+	}
+}
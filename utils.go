@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -58,13 +59,15 @@ func ToStringSlice(_v interface{}) (v []string, err error) {
 
 // ToIntSlice does the best to convert a certain value to []int.
 //
-// If the value is string, they are separated by the comma.
+// If the value is string, they are separated by the comma. If the value is
+// []string, each element is parsed in turn.
 func ToIntSlice(_v interface{}) (v []int, err error) {
 	switch vv := _v.(type) {
 	case string:
-		vs := strings.Split(vv, ",")
-		v = make([]int, 0, len(vs))
-		for _, s := range vs {
+		return ToIntSlice(strings.Split(vv, ","))
+	case []string:
+		v = make([]int, 0, len(vv))
+		for _, s := range vv {
 			if s = strings.TrimSpace(s); s == "" {
 				continue
 			}
@@ -85,13 +88,15 @@ func ToIntSlice(_v interface{}) (v []int, err error) {
 
 // ToInt64Slice does the best to convert a certain value to []int64.
 //
-// If the value is string, they are separated by the comma.
+// If the value is string, they are separated by the comma. If the value is
+// []string, each element is parsed in turn.
 func ToInt64Slice(_v interface{}) (v []int64, err error) {
 	switch vv := _v.(type) {
 	case string:
-		vs := strings.Split(vv, ",")
-		v = make([]int64, 0, len(vs))
-		for _, s := range vs {
+		return ToInt64Slice(strings.Split(vv, ","))
+	case []string:
+		v = make([]int64, 0, len(vv))
+		for _, s := range vv {
 			if s = strings.TrimSpace(s); s == "" {
 				continue
 			}
@@ -112,13 +117,15 @@ func ToInt64Slice(_v interface{}) (v []int64, err error) {
 
 // ToUintSlice does the best to convert a certain value to []uint.
 //
-// If the value is string, they are separated by the comma.
+// If the value is string, they are separated by the comma. If the value is
+// []string, each element is parsed in turn.
 func ToUintSlice(_v interface{}) (v []uint, err error) {
 	switch vv := _v.(type) {
 	case string:
-		vs := strings.Split(vv, ",")
-		v = make([]uint, 0, len(vs))
-		for _, s := range vs {
+		return ToUintSlice(strings.Split(vv, ","))
+	case []string:
+		v = make([]uint, 0, len(vv))
+		for _, s := range vv {
 			if s = strings.TrimSpace(s); s == "" {
 				continue
 			}
@@ -139,13 +146,15 @@ func ToUintSlice(_v interface{}) (v []uint, err error) {
 
 // ToUint64Slice does the best to convert a certain value to []uint64.
 //
-// If the value is string, they are separated by the comma.
+// If the value is string, they are separated by the comma. If the value is
+// []string, each element is parsed in turn.
 func ToUint64Slice(_v interface{}) (v []uint64, err error) {
 	switch vv := _v.(type) {
 	case string:
-		vs := strings.Split(vv, ",")
-		v = make([]uint64, 0, len(vs))
-		for _, s := range vs {
+		return ToUint64Slice(strings.Split(vv, ","))
+	case []string:
+		v = make([]uint64, 0, len(vv))
+		for _, s := range vv {
 			if s = strings.TrimSpace(s); s == "" {
 				continue
 			}
@@ -166,13 +175,15 @@ func ToUint64Slice(_v interface{}) (v []uint64, err error) {
 
 // ToFloat64Slice does the best to convert a certain value to []float64.
 //
-// If the value is string, they are separated by the comma.
+// If the value is string, they are separated by the comma. If the value is
+// []string, each element is parsed in turn.
 func ToFloat64Slice(_v interface{}) (v []float64, err error) {
 	switch vv := _v.(type) {
 	case string:
-		vs := strings.Split(vv, ",")
-		v = make([]float64, 0, len(vs))
-		for _, s := range vs {
+		return ToFloat64Slice(strings.Split(vv, ","))
+	case []string:
+		v = make([]float64, 0, len(vv))
+		for _, s := range vv {
 			if s = strings.TrimSpace(s); s == "" {
 				continue
 			}
@@ -193,14 +204,15 @@ func ToFloat64Slice(_v interface{}) (v []float64, err error) {
 
 // ToTimes does the best to convert a certain value to []time.Time.
 //
-// If the value is string, they are separated by the comma and the each value
-// is parsed by the format, layout.
+// If the value is string, they are separated by the comma. If the value is
+// []string, each element is parsed in turn. And the each value is parsed by the format, layout.
 func ToTimes(layout string, _v interface{}) (v []time.Time, err error) {
 	switch vv := _v.(type) {
 	case string:
-		vs := strings.Split(vv, ",")
-		v = make([]time.Time, 0, len(vs))
-		for _, s := range vs {
+		return ToTimes(layout, strings.Split(vv, ","))
+	case []string:
+		v = make([]time.Time, 0, len(vv))
+		for _, s := range vv {
 			if s = strings.TrimSpace(s); s == "" {
 				continue
 			}
@@ -221,14 +233,15 @@ func ToTimes(layout string, _v interface{}) (v []time.Time, err error) {
 
 // ToDurations does the best to convert a certain value to []time.Duration.
 //
-// If the value is string, they are separated by the comma and the each value
-// is parsed by time.ParseDuration().
+// If the value is string, they are separated by the comma. If the value is
+// []string, each element is parsed in turn. And the each value is parsed by time.ParseDuration().
 func ToDurations(_v interface{}) (v []time.Duration, err error) {
 	switch vv := _v.(type) {
 	case string:
-		vs := strings.Split(vv, ",")
-		v = make([]time.Duration, 0, len(vs))
-		for _, s := range vs {
+		return ToDurations(strings.Split(vv, ","))
+	case []string:
+		v = make([]time.Duration, 0, len(vv))
+		for _, s := range vv {
 			if s = strings.TrimSpace(s); s == "" {
 				continue
 			}
@@ -246,3 +259,33 @@ func ToDurations(_v interface{}) (v []time.Duration, err error) {
 	}
 	return
 }
+
+// ToStringMap does the best to convert a certain value to map[string]string.
+//
+// If the value is string, the key-value pairs are separated by the comma.
+// If the value is []string, each element is a key-value pair. And the key
+// and the value are separated by the equal sign, such as "k1=v1,k2=v2".
+func ToStringMap(_v interface{}) (v map[string]string, err error) {
+	switch vv := _v.(type) {
+	case string:
+		return ToStringMap(strings.Split(vv, ","))
+	case []string:
+		v = make(map[string]string, len(vv))
+		for _, s := range vv {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+
+			kv := strings.SplitN(s, "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+				return nil, fmt.Errorf("invalid key-value pair '%s'", s)
+			}
+			v[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	case map[string]string:
+		v = vv
+	default:
+		err = types.ErrUnknownType
+	}
+	return
+}
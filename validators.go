@@ -22,6 +22,7 @@ import (
 	"net/mail"
 	"net/url"
 	"regexp"
+	"strings"
 )
 
 // Validator is an interface to validate whether the value v is valid.
@@ -161,6 +162,41 @@ func toFloat64(v interface{}) (float64, error) {
 	}
 }
 
+// ChoicesValidator is a Validator which only allows the values in the choices.
+type ChoicesValidator interface {
+	Validator
+
+	// Choices returns all the allowed values.
+	Choices() []string
+}
+
+type describedValidator struct {
+	Validator
+	desc string
+}
+
+func (v describedValidator) String() string {
+	return v.desc
+}
+
+// NewDescribedValidator returns a new validator with the description of
+// the constraint, such as "in [1, 10]", which is returned by the method String
+// and displayed in the usage.
+//
+// All the builtin validators have been described.
+func NewDescribedValidator(desc string, v Validator) Validator {
+	return describedValidator{Validator: v, desc: desc}
+}
+
+type strArrayValidator struct {
+	describedValidator
+	array []string
+}
+
+func (v strArrayValidator) Choices() []string {
+	return append([]string{}, v.array...)
+}
+
 // ValidatorFunc is a wrapper of a function validator.
 type ValidatorFunc func(group, name string, v interface{}) error
 
@@ -172,7 +208,8 @@ func (f ValidatorFunc) Validate(group, name string, v interface{}) error {
 // NewStrLenValidator returns a validator to validate that the length of the
 // string must be between min and max.
 func NewStrLenValidator(min, max int) Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	desc := fmt.Sprintf("length in [%d, %d]", min, max)
+	return NewDescribedValidator(desc, ValidatorFunc(func(group, name string, v interface{}) error {
 		s, err := toString(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -185,13 +222,13 @@ func NewStrLenValidator(min, max int) Validator {
 				s, _len, min, max)
 		}
 		return nil
-	})
+	}))
 }
 
 // NewStrNotEmptyValidator returns a validator to validate that the value must
 // not be an empty string.
 func NewStrNotEmptyValidator() Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	return NewDescribedValidator("not empty", ValidatorFunc(func(group, name string, v interface{}) error {
 		s, err := toString(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -201,13 +238,16 @@ func NewStrNotEmptyValidator() Validator {
 			return NewValidatorError(group, name, v, errStrEmtpy)
 		}
 		return nil
-	})
+	}))
 }
 
 // NewStrArrayValidator returns a validator to validate that the value is in
 // the array.
+//
+// The returned validator has also implemented the interface ChoicesValidator.
 func NewStrArrayValidator(array []string) Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	desc := fmt.Sprintf("one of %s", strings.Join(array, "|"))
+	validator := ValidatorFunc(func(group, name string, v interface{}) error {
 		s, err := toString(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -219,6 +259,11 @@ func NewStrArrayValidator(array []string) Validator {
 		}
 		return NewValidatorErrorf(group, name, "the value %s is not in %v", s, array)
 	})
+
+	return strArrayValidator{
+		describedValidator: describedValidator{Validator: validator, desc: desc},
+		array:              append([]string{}, array...),
+	}
 }
 
 // NewRegexpValidator returns a validator to validate whether the value match
@@ -226,7 +271,8 @@ func NewStrArrayValidator(array []string) Validator {
 //
 // This validator uses regexp.MatchString(pattern, s) to validate it.
 func NewRegexpValidator(pattern string) Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	desc := fmt.Sprintf("match /%s/", pattern)
+	return NewDescribedValidator(desc, ValidatorFunc(func(group, name string, v interface{}) error {
 		s, err := toString(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -239,12 +285,12 @@ func NewRegexpValidator(pattern string) Validator {
 				"'%s' doesn't match the value '%s'", s, pattern)
 		}
 		return nil
-	})
+	}))
 }
 
 // NewURLValidator returns a validator to validate whether a url is valid.
 func NewURLValidator() Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	return NewDescribedValidator("url", ValidatorFunc(func(group, name string, v interface{}) error {
 		s, err := toString(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -253,12 +299,12 @@ func NewURLValidator() Validator {
 			return NewValidatorError(group, name, v, err)
 		}
 		return nil
-	})
+	}))
 }
 
 // NewIPValidator returns a validator to validate whether an ip is valid.
 func NewIPValidator() Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	return NewDescribedValidator("ip", ValidatorFunc(func(group, name string, v interface{}) error {
 		s, err := toString(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -267,7 +313,7 @@ func NewIPValidator() Validator {
 			return NewValidatorErrorf(group, name, v, "the value is not a valid ip")
 		}
 		return nil
-	})
+	}))
 }
 
 // NewIntegerRangeValidator returns a validator to validate whether the integer
@@ -276,7 +322,8 @@ func NewIPValidator() Validator {
 // This validator can be used to validate the value of the type int, int8,
 // int16, int32, int64, uint, uint8, uint16, uint32, uint64.
 func NewIntegerRangeValidator(min, max int64) Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	desc := fmt.Sprintf("in [%d, %d]", min, max)
+	return NewDescribedValidator(desc, ValidatorFunc(func(group, name string, v interface{}) error {
 		i, err := toInt64(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -286,7 +333,7 @@ func NewIntegerRangeValidator(min, max int64) Validator {
 				"the value %d is not between %d and %d", i, min, max)
 		}
 		return nil
-	})
+	}))
 }
 
 // NewFloatRangeValidator returns a validator to validate whether the float
@@ -295,7 +342,8 @@ func NewIntegerRangeValidator(min, max int64) Validator {
 // This validator can be used to validate the value of the type float32 and
 // float64.
 func NewFloatRangeValidator(min, max float64) Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	desc := fmt.Sprintf("in [%v, %v]", min, max)
+	return NewDescribedValidator(desc, ValidatorFunc(func(group, name string, v interface{}) error {
 		f, err := toFloat64(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -305,7 +353,7 @@ func NewFloatRangeValidator(min, max float64) Validator {
 				"the value %f is not between %f and %f", f, min, max)
 		}
 		return nil
-	})
+	}))
 }
 
 // NewPortValidator returns a validator to validate whether a port is between
@@ -316,7 +364,7 @@ func NewPortValidator() Validator {
 
 // NewEmailValidator returns a validator to validate whether an email is valid.
 func NewEmailValidator() Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	return NewDescribedValidator("email", ValidatorFunc(func(group, name string, v interface{}) error {
 		s, err := toString(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -325,7 +373,7 @@ func NewEmailValidator() Validator {
 			return NewValidatorError(group, name, v, err)
 		}
 		return nil
-	})
+	}))
 }
 
 // NewAddressValidator returns a validator to validate whether an address is
@@ -333,7 +381,7 @@ func NewEmailValidator() Validator {
 //
 // This validator uses net.SplitHostPort() to validate it.
 func NewAddressValidator() Validator {
-	return ValidatorFunc(func(group, name string, v interface{}) error {
+	return NewDescribedValidator("host:port", ValidatorFunc(func(group, name string, v interface{}) error {
 		s, err := toString(v)
 		if err != nil {
 			return NewValidatorError(group, name, v, err)
@@ -342,5 +390,5 @@ func NewAddressValidator() Validator {
 			return NewValidatorError(group, name, v, err)
 		}
 		return nil
-	})
+	}))
 }
@@ -0,0 +1,173 @@
+/*
+Copyright 2017 xgfone
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+)
+
+var (
+	// ErrVersion is returned by Config.Parse when giving the version option,
+	// after the version handler has printed the version.
+	ErrVersion = fmt.Errorf("the version is requested")
+
+	// ErrHelp is returned by Config.Parse when giving the help option,
+	// after the help handler has printed the usage. It is flag.ErrHelp.
+	ErrHelp = flag.ErrHelp
+)
+
+// VersionInfo is the information of the version, which contains the build
+// metadata from runtime/debug.ReadBuildInfo.
+//
+// CommitTime is the time of the VCS commit, not the build time, which Go
+// does not record.
+type VersionInfo struct {
+	Version    string `json:"version"`
+	Module     string `json:"module,omitempty"`
+	GoVersion  string `json:"go_version,omitempty"`
+	Revision   string `json:"revision,omitempty"`
+	CommitTime string `json:"commit_time,omitempty"`
+	Dirty      bool   `json:"dirty,omitempty"`
+}
+
+// String returns the text format of the version information,
+// such as "1.0.0 (revision 0123abc, dirty, committed at 2017-01-01T00:00:00Z, go1.18)".
+func (vi VersionInfo) String() string {
+	var infos []string
+	if vi.Revision != "" {
+		infos = append(infos, "revision "+vi.Revision)
+	}
+	if vi.Dirty {
+		infos = append(infos, "dirty")
+	}
+	if vi.CommitTime != "" {
+		infos = append(infos, "committed at "+vi.CommitTime)
+	}
+	if vi.GoVersion != "" {
+		infos = append(infos, vi.GoVersion)
+	}
+
+	if len(infos) == 0 {
+		return vi.Version
+	}
+	return fmt.Sprintf("%s (%s)", vi.Version, strings.Join(infos, ", "))
+}
+
+// GetVersionInfo returns the information of the version set by SetVersion
+// with the build metadata.
+func (c *Config) GetVersionInfo() VersionInfo {
+	vi := VersionInfo{Version: c.vVersion}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		vi.Module = bi.Main.Path
+		vi.GoVersion = bi.GoVersion
+		if vi.Version == "" {
+			vi.Version = bi.Main.Version
+		}
+
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				vi.Revision = s.Value
+			case "vcs.time":
+				vi.CommitTime = s.Value
+			case "vcs.modified":
+				vi.Dirty = s.Value == "true"
+			}
+		}
+	}
+	return vi
+}
+
+// SetVersionHandler sets the handler to print the version when giving
+// the version option, the format of which is the value of the option,
+// such as "" for "--version" and "json" for "--version=json".
+//
+// The default handler prints GetVersionInfo() to os.Stdout in the format
+// of text or JSON.
+func (c *Config) SetVersionHandler(handler func(c *Config, format string) error) *Config {
+	c.versionHandler = handler
+	return c
+}
+
+// SetHelpHandler sets the handler to print the help when giving the help
+// option, which is Config.PrintUsage by default.
+func (c *Config) SetHelpHandler(handler func(c *Config) error) *Config {
+	c.helpHandler = handler
+	return c
+}
+
+// handleVersion calls the version handler, and returns ErrVersion or the error
+// returned by the handler, which is called by the CLI parser.
+func (c *Config) handleVersion(format string) error {
+	handler := c.versionHandler
+	if handler == nil {
+		handler = printVersion
+	}
+
+	if err := handler(c, format); err != nil {
+		return err
+	}
+	return ErrVersion
+}
+
+// handleHelp calls the help handler, which is called by the CLI parser.
+func (c *Config) handleHelp() error {
+	if c.helpHandler == nil {
+		return c.PrintUsage()
+	}
+	return c.helpHandler(c)
+}
+
+func printVersion(c *Config, format string) error {
+	vi := c.GetVersionInfo()
+	switch format {
+	case "", "text":
+		_, err := fmt.Fprintln(os.Stdout, vi.String())
+		return err
+	case "json":
+		return json.NewEncoder(os.Stdout).Encode(vi)
+	default:
+		return fmt.Errorf("unsupported version format '%s'", format)
+	}
+}
+
+// versionValue is the flag.Value of the version option, which is used as
+// "-version" or "-version=json".
+type versionValue struct {
+	set    bool
+	format string
+}
+
+func (v *versionValue) IsBoolFlag() bool { return true }
+func (v *versionValue) String() string   { return "" }
+func (v *versionValue) Type() string     { return "string" }
+func (v *versionValue) Set(value string) error {
+	switch value {
+	case "true":
+		v.set, v.format = true, ""
+	case "false":
+		v.set, v.format = false, ""
+	default:
+		v.set, v.format = true, value
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestVersionHandler(t *testing.T) {
+	parsers := []func() Parser{
+		func() Parser { return NewGetoptCliParser(true) },
+		func() Parser { return NewFlagCliParser(flag.NewFlagSet("test", flag.ContinueOnError), false) },
+	}
+	args := [][]string{{"--version=json"}, {"-version=json"}}
+
+	for i, newParser := range parsers {
+		var format string
+		conf := NewConfig().AddParser(newParser()).SetVersion("1.0.0")
+		conf.SetVersionHandler(func(c *Config, f string) error {
+			format = f
+			return nil
+		})
+
+		if err := conf.Parse(args[i]...); err != ErrVersion {
+			t.Errorf("%d: expect ErrVersion, but got %v", i, err)
+		} else if format != "json" {
+			t.Errorf("%d: expect the format '%s', but got '%s'", i, "json", format)
+		}
+	}
+
+	// The flag set like flag.CommandLine exits on error by default.
+	parsers = append(parsers, func() Parser {
+		return NewFlagCliParser(flag.NewFlagSet("test", flag.ExitOnError), false)
+	})
+	for i, newParser := range parsers {
+		var helped bool
+		conf := NewConfig().AddParser(newParser())
+		conf.SetHelpHandler(func(c *Config) error { helped = true; return nil })
+		if err := conf.Parse("-h"); err != ErrHelp {
+			t.Errorf("%d: expect ErrHelp, but got %v", i, err)
+		} else if !helped {
+			t.Errorf("%d: the help handler is not called", i)
+		}
+	}
+}
+
+func TestVersionInfoString(t *testing.T) {
+	vi := VersionInfo{Version: "1.0.0"}
+	if s := vi.String(); s != "1.0.0" {
+		t.Errorf("expect '%s', but got '%s'", "1.0.0", s)
+	}
+
+	vi = VersionInfo{Version: "1.0.0", Revision: "abc", Dirty: true, GoVersion: "go1.18",
+		CommitTime: "2017-01-01T00:00:00Z"}
+	if s, e := vi.String(), "1.0.0 (revision abc, dirty, committed at 2017-01-01T00:00:00Z, go1.18)"; s != e {
+		t.Errorf("expect '%s', but got '%s'", e, s)
+	}
+}